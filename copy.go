@@ -0,0 +1,315 @@
+package kin
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CopyFormat is the format rows are written in by CopyTo.
+type CopyFormat int
+
+const (
+	// CopyText writes rows in Postgres' text COPY format: tab-separated
+	// values with \N for NULL.
+	CopyText CopyFormat = iota
+
+	// CopyCSV writes rows as comma-separated values with NULL written as an
+	// unquoted empty value.
+	CopyCSV
+
+	// CopyCSVHeader is like CopyCSV, but the first line contains the names of
+	// the columns.
+	CopyCSVHeader
+)
+
+// copyFrom loads models into their table with COPY ... FROM STDIN. The
+// columns that are copied are the ones set in the first model, and every
+// other model must provide those same columns. COPY can't fall back to a
+// column's default like InsertMany does, so a later model that sets a column
+// the first one didn't is an error rather than being silently dropped.
+func copyFrom(ctx context.Context, tx *sql.Tx, next func() (Model, bool, error)) (int64, error) {
+	first, ok, err := next()
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	var columns []string
+	for _, column := range first.Columns() {
+		if column.IsSet() {
+			columns = append(columns, column.FieldName())
+		}
+	}
+
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("no columns set to copy in %s", first.TableName())
+	}
+
+	stmt, err := tx.PrepareContext(ctx, copyInStmt(first.TableName(), columns))
+	if err != nil {
+		return 0, wrapError(ctx, err)
+	}
+	defer stmt.Close()
+
+	var count int64
+	for m := first; ok; m, ok, err = next() {
+		if m.TableName() != first.TableName() {
+			return count, fmt.Errorf("models must all belong to %s, found %s", first.TableName(), m.TableName())
+		}
+
+		fields := map[string]FieldBuilder{}
+		for _, column := range m.Columns() {
+			if column.IsSet() && !contains(columns, column.FieldName()) {
+				return count, fmt.Errorf("column %s is set, but isn't copied into %s because it isn't set in the first model", column.FieldName(), m.TableName())
+			}
+			fields[column.FieldName()] = column
+		}
+
+		values := make([]interface{}, len(columns))
+		for i, name := range columns {
+			column, ok := fields[name]
+			if !ok {
+				return count, fmt.Errorf("column %s not found in %s", name, m.TableName())
+			}
+			values[i] = paramValue(column)
+		}
+
+		if _, err := stmt.ExecContext(ctx, values...); err != nil {
+			return count, wrapError(ctx, err)
+		}
+		count++
+	}
+
+	if err != nil {
+		return count, err
+	}
+
+	// An exec without any values flushes the buffered rows to the database.
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return count, wrapError(ctx, err)
+	}
+
+	return count, nil
+}
+
+func copyInStmt(table string, columns []string) string {
+	if parts := strings.SplitN(table, ".", 2); len(parts) == 2 {
+		return pq.CopyInSchema(parts[0], parts[1], columns...)
+	}
+
+	return pq.CopyIn(table, columns...)
+}
+
+// modelSlice iterates over a slice of models for copyFrom.
+func modelSlice(models []Model) func() (Model, bool, error) {
+	i := 0
+	return func() (Model, bool, error) {
+		if i >= len(models) {
+			return nil, false, nil
+		}
+
+		m := models[i]
+		i++
+		return m, true, nil
+	}
+}
+
+// modelChan iterates over a channel of models for copyFrom until the channel
+// is closed or the context is done.
+func modelChan(ctx context.Context, models <-chan Model) func() (Model, bool, error) {
+	return func() (Model, bool, error) {
+		select {
+		case m, ok := <-models:
+			return m, ok, nil
+		case <-ctx.Done():
+			return nil, false, wrapError(ctx, ctx.Err())
+		}
+	}
+}
+
+// copyFromDB runs copyFrom inside of its own transaction.
+func copyFromDB(ctx context.Context, db *sql.DB, next func() (Model, bool, error)) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, wrapError(ctx, err)
+	}
+
+	count, err := copyFrom(ctx, tx, next)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, wrapError(ctx, err)
+	}
+
+	return count, nil
+}
+
+// copyTo runs the query and writes each row to w in the chosen COPY format.
+// The driver doesn't support COPY ... TO STDOUT, so the rows are encoded
+// client-side, with values formatted the way Postgres formats them in COPY
+// output.
+func copyTo(ctx context.Context, q *Query, w io.Writer, format CopyFormat) (int64, error) {
+	var encode func([][]byte) string
+	switch format {
+	case CopyText:
+		encode = encodeCopyText
+	case CopyCSV, CopyCSVHeader:
+		encode = encodeCopyCSV
+	default:
+		return 0, fmt.Errorf("unknown copy format %d", format)
+	}
+
+	rows, err := q.query(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, wrapError(ctx, err)
+	}
+
+	buf := bufio.NewWriter(w)
+
+	if format == CopyCSVHeader {
+		header := make([][]byte, len(types))
+		for i, t := range types {
+			header[i] = []byte(t.Name())
+		}
+
+		if _, err := buf.WriteString(encode(header)); err != nil {
+			return 0, err
+		}
+	}
+
+	// Columns are scanned by position, since a query may return more than
+	// one column with the same name.
+	values := make([]interface{}, len(types))
+	dest := make([]interface{}, len(types))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var count int64
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return count, wrapError(ctx, err)
+		}
+
+		fields := make([][]byte, len(values))
+		for i, value := range values {
+			fields[i] = copyValue(value, types[i].DatabaseTypeName())
+		}
+
+		if _, err := buf.WriteString(encode(fields)); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	if err := rows.Err(); err != nil {
+		return count, wrapError(ctx, err)
+	}
+
+	return count, buf.Flush()
+}
+
+// copyValue formats a value decoded by the driver the way Postgres writes it
+// in COPY output. A nil result is NULL.
+func copyValue(value interface{}, dbType string) []byte {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		if dbType == "BYTEA" {
+			return []byte(`\x` + hex.EncodeToString(v))
+		}
+		return v
+	case string:
+		return []byte(v)
+	case bool:
+		if v {
+			return []byte("t")
+		}
+		return []byte("f")
+	case int64:
+		return []byte(strconv.FormatInt(v, 10))
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'g', -1, 64))
+	case time.Time:
+		return []byte(copyTime(v, dbType))
+	default:
+		return []byte(fmt.Sprint(v))
+	}
+}
+
+func copyTime(t time.Time, dbType string) string {
+	switch dbType {
+	case "DATE":
+		return t.Format("2006-01-02")
+	case "TIME":
+		return t.Format("15:04:05.999999")
+	case "TIMETZ":
+		return t.Format("15:04:05.999999") + copyOffset(t)
+	case "TIMESTAMP":
+		return t.Format("2006-01-02 15:04:05.999999")
+	default:
+		return t.Format("2006-01-02 15:04:05.999999") + copyOffset(t)
+	}
+}
+
+// copyOffset formats the time's UTC offset like Postgres, which leaves out
+// minutes when they're zero, such as +00 or +05:30.
+func copyOffset(t time.Time) string {
+	return strings.TrimSuffix(t.Format("-07:00"), ":00")
+}
+
+var copyTextEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"\t", `\t`,
+	"\n", `\n`,
+	"\r", `\r`,
+)
+
+func encodeCopyText(values [][]byte) string {
+	fields := make([]string, len(values))
+	for i, value := range values {
+		if value == nil {
+			fields[i] = `\N`
+			continue
+		}
+
+		fields[i] = copyTextEscaper.Replace(string(value))
+	}
+
+	return strings.Join(fields, "\t") + "\n"
+}
+
+func encodeCopyCSV(values [][]byte) string {
+	fields := make([]string, len(values))
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+
+		field := string(value)
+		if field == "" || field == `\.` || strings.ContainsAny(field, ",\"\r\n") {
+			field = `"` + strings.Replace(field, `"`, `""`, -1) + `"`
+		}
+		fields[i] = field
+	}
+
+	return strings.Join(fields, ",") + "\n"
+}
@@ -0,0 +1,236 @@
+package kin
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestEncodeCopyText(t *testing.T) {
+	values := [][]byte{[]byte("1"), nil, []byte("tab\there"), []byte(`back\slash`), []byte("")}
+	want := "1\t\\N\ttab\\there\tback\\\\slash\t\n"
+
+	if got := encodeCopyText(values); got != want {
+		t.Errorf("encodeCopyText(...) = %q, want %q", got, want)
+	}
+}
+
+func TestEncodeCopyCSV(t *testing.T) {
+	values := [][]byte{[]byte("1"), nil, []byte(""), []byte(`say "hi", bye`), []byte("plain")}
+	want := "1,,\"\",\"say \"\"hi\"\", bye\",plain\n"
+
+	if got := encodeCopyCSV(values); got != want {
+		t.Errorf("encodeCopyCSV(...) = %q, want %q", got, want)
+	}
+}
+
+func TestCopyInStmt(t *testing.T) {
+	tests := []struct {
+		table string
+		want  string
+	}{
+		{"test_model", `COPY "test_model" ("id", "name") FROM STDIN`},
+		{"public.test_model", `COPY "public"."test_model" ("id", "name") FROM STDIN`},
+	}
+
+	for _, test := range tests {
+		if got := copyInStmt(test.table, []string{"id", "name"}); got != test.want {
+			t.Errorf("copyInStmt(%s, ...) = %s, want %s", test.table, got, test.want)
+		}
+	}
+}
+
+func TestCopyToDuplicateColumns(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, err := NewConnection(connStr)
+	if err != nil {
+		t.Errorf("NewConnection(%s) = %v, want <nil>", connStr, err)
+		return
+	}
+	defer db.Close()
+
+	var buf bytes.Buffer
+	stmt := "SELECT a.id, b.id, '\\x0102'::bytea AS data, '2021-03-04 05:06:07.5+00'::timestamptz AS at FROM (SELECT 1 AS id) a, (SELECT 2 AS id) b"
+	if _, err := db.CopyTo(&buf, CopyText, stmt); err != nil {
+		t.Errorf("db.CopyTo(...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	// The time zone depends on the session, so only the date is compared.
+	want := "1\t2\t\\\\x0102\t2021-03-0"
+	if got := buf.String(); len(got) < len(want) || got[:len(want)] != want {
+		t.Errorf("db.CopyTo(...) wrote %q, want prefix %q", got, want)
+	}
+}
+
+func TestCopyValue(t *testing.T) {
+	at := time.Date(2021, 3, 4, 5, 6, 7, 500000000, time.FixedZone("", 5*3600+1800))
+	tests := []struct {
+		value  interface{}
+		dbType string
+		want   string
+	}{
+		{[]byte{1, 0xff}, "BYTEA", `\x01ff`},
+		{[]byte("text"), "TEXT", "text"},
+		{true, "BOOL", "t"},
+		{int64(42), "INT8", "42"},
+		{1.5, "FLOAT8", "1.5"},
+		{at, "DATE", "2021-03-04"},
+		{at, "TIME", "05:06:07.5"},
+		{at, "TIMESTAMP", "2021-03-04 05:06:07.5"},
+		{at, "TIMESTAMPTZ", "2021-03-04 05:06:07.5+05:30"},
+		{at.UTC(), "TIMESTAMPTZ", "2021-03-03 23:36:07.5+00"},
+	}
+
+	for _, test := range tests {
+		if got := string(copyValue(test.value, test.dbType)); got != test.want {
+			t.Errorf("copyValue(%v, %s) = %s, want %s", test.value, test.dbType, got, test.want)
+		}
+	}
+
+	if got := copyValue(nil, "TEXT"); got != nil {
+		t.Errorf("copyValue(nil, TEXT) = %q, want nil", got)
+	}
+}
+
+type testCopyModel struct {
+	ID        int
+	Name      string
+	CreatedAt *time.Time
+}
+
+func (tm *testCopyModel) TableName() string {
+	return "test_copy"
+}
+
+func (tm *testCopyModel) Columns() []FieldBuilder {
+	return []FieldBuilder{
+		IntField("id", &tm.ID),
+		StringField("name", &tm.Name),
+		NullTimeField("created_at", &tm.CreatedAt),
+	}
+}
+
+func TestCopyFrom(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, err := NewConnection(connStr)
+	if err != nil {
+		t.Errorf("NewConnection(%s) = %v, want <nil>", connStr, err)
+		return
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("DROP TABLE IF EXISTS test_copy; CREATE TABLE test_copy (id int PRIMARY KEY, name text NOT NULL, created_at timestamptz)"); err != nil {
+		t.Errorf("db.Exec(CREATE TABLE test_copy ...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+	defer db.Exec("DROP TABLE test_copy")
+
+	createdAt := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	count, err := db.CopyFrom([]Model{
+		&testCopyModel{ID: 1, Name: "one", CreatedAt: &createdAt},
+		&testCopyModel{ID: 2, Name: "tab\there", CreatedAt: &createdAt},
+	})
+	if count != 2 || err != nil {
+		t.Errorf("db.CopyFrom(...) = (%d, %v), want (2, <nil>)", count, err)
+		return
+	}
+
+	models := make(chan Model, 2)
+	models <- &testCopyModel{ID: 3, Name: "three", CreatedAt: &createdAt}
+	models <- &testCopyModel{ID: 4, Name: "four", CreatedAt: &createdAt}
+	close(models)
+	if count, err := db.CopyFromChan(models); count != 2 || err != nil {
+		t.Errorf("db.CopyFromChan(...) = (%d, %v), want (2, <nil>)", count, err)
+		return
+	}
+
+	// The first model doesn't set created_at, so copying the second model's
+	// value would lose it.
+	_, err = db.CopyFrom([]Model{
+		&testCopyModel{ID: 5, Name: "five"},
+		&testCopyModel{ID: 6, Name: "six", CreatedAt: &createdAt},
+	})
+	if err == nil {
+		t.Error("db.CopyFrom(...) with a column set only in a later model = (_, <nil>), want (_, error)")
+	}
+
+	res, err := db.Query("SELECT * FROM test_copy ORDER BY id").Run()
+	if err != nil {
+		t.Errorf("db.Query(...).Run() = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	want := []string{"one", "tab\there", "three", "four"}
+	if len(res.Rows) != len(want) {
+		t.Errorf("len(res.Rows) = %d, want %d", len(res.Rows), len(want))
+		return
+	}
+
+	for i, row := range res.Rows {
+		if name := row.ExtractString("name"); name != want[i] {
+			t.Errorf("res.Rows[%d] name = %q, want %q", i, name, want[i])
+		}
+
+		if at := row.ExtractTime("created_at"); !at.Equal(createdAt) {
+			t.Errorf("res.Rows[%d] created_at = %v, want %v", i, at, createdAt)
+		}
+	}
+}
+
+func TestTransactionCopyFrom(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, err := NewConnection(connStr)
+	if err != nil {
+		t.Errorf("NewConnection(%s) = %v, want <nil>", connStr, err)
+		return
+	}
+	defer db.Close()
+
+	txn, err := db.StartTransaction()
+	if err != nil {
+		t.Errorf("db.StartTransaction() = (_, %v), want (_, <nil>)", err)
+		return
+	}
+	defer txn.Rollback()
+
+	if _, err := txn.Exec("CREATE TEMP TABLE test_copy (id int PRIMARY KEY, name text NOT NULL, created_at timestamptz)"); err != nil {
+		t.Errorf("txn.Exec(CREATE TEMP TABLE ...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	count, err := txn.CopyFrom([]Model{
+		&testCopyModel{ID: 1, Name: "one"},
+		&testCopyModel{ID: 2, Name: "two"},
+	})
+	if count != 2 || err != nil {
+		t.Errorf("txn.CopyFrom(...) = (%d, %v), want (2, <nil>)", count, err)
+		return
+	}
+
+	res, err := txn.Query("SELECT count(*) AS n FROM test_copy WHERE created_at IS NULL").One()
+	if err != nil {
+		t.Errorf("txn.Query(...).One() = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	if n := res.ExtractInt("n"); n != 2 {
+		t.Errorf("rows copied in the transaction = %d, want 2", n)
+	}
+}
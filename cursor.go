@@ -0,0 +1,152 @@
+package kin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrCursorClosed is returned when a cursor is used after it's been closed,
+// either explicitly or because its transaction ended.
+var ErrCursorClosed = errors.New("cursor is closed")
+
+// Cursor is a server-side cursor declared with DECLARE ... CURSOR. Rows are
+// fetched from the database in batches of the caller's choosing, so a result
+// set of any size can be read with bounded memory, and the position in the
+// result set is kept by the server between calls.
+//
+// A cursor only lives as long as the transaction that declared it: it's
+// closed when the transaction is committed or rolled back.
+//
+//	cursor, err := txn.DeclareCursor("SELECT * FROM events ORDER BY id")
+//	if err != nil {
+//		return err
+//	}
+//	defer cursor.Close()
+//
+//	for {
+//		rows, err := cursor.Fetch(1000)
+//		if err != nil {
+//			return err
+//		}
+//
+//		if len(rows) == 0 {
+//			return nil
+//		}
+//
+//		// Process the batch.
+//	}
+type Cursor struct {
+	name   string
+	tx     *transaction
+	scroll bool
+	closed bool
+}
+
+// Fetch returns the next n rows from the cursor. It returns fewer rows once
+// the end of the result set is reached, and no rows after that.
+func (c *Cursor) Fetch(n int) ([]*RowResult, error) {
+	return c.FetchContext(context.Background(), n)
+}
+
+// FetchContext is like Fetch, but runs with the provided context.
+func (c *Cursor) FetchContext(ctx context.Context, n int) ([]*RowResult, error) {
+	return c.fetch(ctx, fmt.Sprintf("FETCH FORWARD %d FROM %s", n, c.name))
+}
+
+// FetchBackward returns the previous n rows from the cursor, in reverse
+// order. It's only supported by cursors declared with DeclareScrollCursor.
+func (c *Cursor) FetchBackward(n int) ([]*RowResult, error) {
+	return c.FetchBackwardContext(context.Background(), n)
+}
+
+// FetchBackwardContext is like FetchBackward, but runs with the provided
+// context.
+func (c *Cursor) FetchBackwardContext(ctx context.Context, n int) ([]*RowResult, error) {
+	if !c.scroll {
+		return nil, fmt.Errorf("cursor %s can't fetch backward without being declared with DeclareScrollCursor", c.name)
+	}
+
+	return c.fetch(ctx, fmt.Sprintf("FETCH BACKWARD %d FROM %s", n, c.name))
+}
+
+// Move repositions the cursor by n rows without returning them. A negative n
+// moves the cursor backward, which is only supported by cursors declared with
+// DeclareScrollCursor.
+func (c *Cursor) Move(n int) error {
+	return c.MoveContext(context.Background(), n)
+}
+
+// MoveContext is like Move, but runs with the provided context.
+func (c *Cursor) MoveContext(ctx context.Context, n int) error {
+	if c.closed {
+		return ErrCursorClosed
+	}
+
+	if n < 0 && !c.scroll {
+		return fmt.Errorf("cursor %s can't move backward without being declared with DeclareScrollCursor", c.name)
+	}
+
+	_, err := c.tx.ExecContext(ctx, fmt.Sprintf("MOVE RELATIVE %d FROM %s", n, c.name))
+	return err
+}
+
+// Close closes the cursor and releases its resources on the server. It's
+// safe to call multiple times, and after the cursor's transaction has ended.
+func (c *Cursor) Close() error {
+	if c.closed {
+		return nil
+	}
+
+	c.closed = true
+	_, err := c.tx.Exec("CLOSE " + c.name)
+	return err
+}
+
+func (c *Cursor) fetch(ctx context.Context, stmt string) ([]*RowResult, error) {
+	if c.closed {
+		return nil, ErrCursorClosed
+	}
+
+	// FETCH can't be prepared, and without parameters the driver sends it
+	// with the simple query protocol.
+	rows, err := c.tx.tx.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, wrapError(ctx, err)
+	}
+
+	res, err := newResult(rows)
+	if err != nil {
+		return nil, wrapError(ctx, err)
+	}
+
+	return res.Rows, nil
+}
+
+// declareCursor declares a cursor for the query inside of the transaction.
+func declareCursor(ctx context.Context, t *transaction, scroll bool, stmt string, params ...interface{}) (*Cursor, error) {
+	if t.done {
+		return nil, sql.ErrTxDone
+	}
+
+	*t.names++
+	c := &Cursor{
+		name:   fmt.Sprintf("kin_cursor_%d", *t.names),
+		tx:     t,
+		scroll: scroll,
+	}
+
+	mode := "NO SCROLL"
+	if scroll {
+		mode = "SCROLL"
+	}
+
+	declare := fmt.Sprintf("DECLARE %s %s CURSOR FOR %s", c.name, mode, stmt)
+	if _, err := t.ExecContext(ctx, declare, params...); err != nil {
+		return nil, err
+	}
+
+	t.cursors = append(t.cursors, c)
+	return c, nil
+}
@@ -0,0 +1,99 @@
+package kin
+
+import (
+	"os"
+	"testing"
+
+	_ "github.com/jmataya/renv/autoload"
+)
+
+func TestCursor(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, err := NewConnection(connStr)
+	if err != nil {
+		t.Errorf("NewConnection(%s) = %v, want <nil>", connStr, err)
+		return
+	}
+	defer db.Close()
+
+	txn, err := db.StartTransaction()
+	if err != nil {
+		t.Errorf("db.StartTransaction() = (_, %v), want (_, <nil>)", err)
+		return
+	}
+	defer txn.Rollback()
+
+	cursor, err := txn.DeclareScrollCursor("SELECT n FROM generate_series(1, $1) AS n", 5)
+	if err != nil {
+		t.Errorf("txn.DeclareScrollCursor(...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	tests := []struct {
+		fetch func() ([]*RowResult, error)
+		want  []int
+	}{
+		{func() ([]*RowResult, error) { return cursor.Fetch(2) }, []int{1, 2}},
+		{func() ([]*RowResult, error) { return cursor.Fetch(2) }, []int{3, 4}},
+		{func() ([]*RowResult, error) { return cursor.FetchBackward(2) }, []int{3, 2}},
+		{func() ([]*RowResult, error) { return cursor.Fetch(10) }, []int{3, 4, 5}},
+		{func() ([]*RowResult, error) { return cursor.Fetch(10) }, []int{}},
+	}
+
+	for i, test := range tests {
+		rows, err := test.fetch()
+		if err != nil {
+			t.Errorf("fetch %d = (_, %v), want (_, <nil>)", i, err)
+			return
+		}
+
+		if len(rows) != len(test.want) {
+			t.Errorf("fetch %d returned %d rows, want %d", i, len(rows), len(test.want))
+			continue
+		}
+
+		for j, row := range rows {
+			if n := row.ExtractInt("n"); n != test.want[j] {
+				t.Errorf("fetch %d row %d = %d, want %d", i, j, n, test.want[j])
+			}
+		}
+	}
+
+	if err := cursor.Move(-4); err != nil {
+		t.Errorf("cursor.Move(-4) = %v, want <nil>", err)
+	}
+
+	rows, err := cursor.Fetch(1)
+	if err != nil || len(rows) != 1 || rows[0].ExtractInt("n") != 3 {
+		t.Errorf("cursor.Fetch(1) after Move(-4) = (%v, %v), want row 3", rows, err)
+	}
+
+	if err := txn.Rollback(); err != nil {
+		t.Errorf("txn.Rollback() = %v, want <nil>", err)
+	}
+
+	if _, err := cursor.Fetch(1); err != ErrCursorClosed {
+		t.Errorf("cursor.Fetch(1) after rollback = (_, %v), want (_, %v)", err, ErrCursorClosed)
+	}
+
+	if err := cursor.Close(); err != nil {
+		t.Errorf("cursor.Close() after rollback = %v, want <nil>", err)
+	}
+}
+
+func TestCursorNoScroll(t *testing.T) {
+	c := &Cursor{name: "kin_cursor_1"}
+
+	if _, err := c.FetchBackward(1); err == nil {
+		t.Error("c.FetchBackward(1) = (_, <nil>), want (_, error)")
+	}
+
+	if err := c.Move(-1); err == nil {
+		t.Error("c.Move(-1) = <nil>, want error")
+	}
+}
@@ -1,9 +1,13 @@
 package kin
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
+	"time"
 
 	_ "github.com/lib/pq" // Needed to initialize the Postgres SQL driver.
 )
@@ -14,18 +18,165 @@ type Database interface {
 	// Close terminates the database connection.
 	Close() error
 
+	// CopyFrom loads the models into their table using COPY ... FROM STDIN
+	// inside of a transaction. The copied columns are the ones set in the
+	// first model, and every model must provide those columns. A model that
+	// sets any other column is an error. It returns the number of rows copied.
+	CopyFrom(models []Model) (int64, error)
+
+	// CopyFromContext is like CopyFrom, but runs with the provided context.
+	CopyFromContext(ctx context.Context, models []Model) (int64, error)
+
+	// CopyFromChan is like CopyFrom, but reads models from a channel until
+	// it's closed.
+	CopyFromChan(models <-chan Model) (int64, error)
+
+	// CopyFromChanContext is like CopyFromChan, but runs with the provided
+	// context.
+	CopyFromChanContext(ctx context.Context, models <-chan Model) (int64, error)
+
+	// CopyTo runs a query and writes its rows to w in the chosen COPY format.
+	// It returns the number of rows written. This isn't a server-side COPY
+	// ... TO STDOUT, which the driver doesn't support: the query's rows are
+	// read as usual and encoded on the client, which is slower for large
+	// exports.
+	CopyTo(w io.Writer, format CopyFormat, stmt string, params ...interface{}) (int64, error)
+
+	// CopyToContext is like CopyTo, but runs with the provided context.
+	CopyToContext(ctx context.Context, w io.Writer, format CopyFormat, stmt string, params ...interface{}) (int64, error)
+
+	// Delete generates a query that deletes a model by its primary key and
+	// returns the deleted row.
+	Delete(m PrimaryKeyer) *Query
+
+	// Exec runs a query against the database that doesn't return any results.
+	// The query isn't prepared, so it may contain multiple statements when
+	// there are no arguments.
+	Exec(query string, args ...interface{}) (*ExecResult, error)
+
+	// ExecContext is like Exec, but runs the query with the provided context.
+	ExecContext(ctx context.Context, query string, args ...interface{}) (*ExecResult, error)
+
+	// Find generates a query that looks up a model by its primary key.
+	Find(m PrimaryKeyer, key interface{}) *Query
+
 	// Insert generates an insert query for a model.
 	Insert(m Model) *Query
 
+	// InsertMany inserts all of the models with as few statements as possible
+	// and updates each model with its inserted row. When more than one
+	// statement is needed, they're run inside a single transaction.
+	InsertMany(models []Model) error
+
+	// InsertManyContext is like InsertMany, but runs with the provided
+	// context.
+	InsertManyContext(ctx context.Context, models []Model) error
+
+	// Listen opens a subscription that receives the notifications sent on
+	// the channels. The subscription uses its own connection to the database,
+	// which requires the database to have been created with NewConnection or
+	// the ListenURL option.
+	Listen(channels ...string) (*Subscription, error)
+
+	// NamedQuery generates a new query with named parameters, written as
+	// :name or @name, that are bound from params. Every named parameter in
+	// the statement must have a value and every value must be used.
+	NamedQuery(stmt string, params map[string]interface{}) *Query
+
+	// NamedModelQuery generates a new query with named parameters, written
+	// as :name or @name, that are bound from the model's columns by their
+	// field names.
+	NamedModelQuery(stmt string, m Model) *Query
+
+	// Notify sends a notification with the payload to the listeners of the
+	// channel.
+	Notify(channel, payload string) error
+
+	// NotifyContext is like Notify, but runs with the provided context.
+	NotifyContext(ctx context.Context, channel, payload string) error
+
 	// Query generates a new query to be executed at a later time.
 	Query(stmt string, params ...interface{}) *Query
 
 	// StartTransaction initiates a database transaction object.
 	StartTransaction() (Transaction, error)
+
+	// StartTransactionContext initiates a database transaction object that is
+	// bound to the provided context. If the context is canceled before the
+	// transaction is committed, the transaction is rolled back.
+	StartTransactionContext(ctx context.Context) (Transaction, error)
+
+	// StartTransactionWithOptions is like StartTransactionContext, but starts
+	// the transaction with the provided options.
+	StartTransactionWithOptions(ctx context.Context, opts TxOptions) (Transaction, error)
+
+	// Update generates a query that updates the set columns of a model by its
+	// primary key and returns the updated row.
+	Update(m PrimaryKeyer) *Query
+
+	// Upsert generates an insert query for a model that resolves conflicts
+	// with existing rows as described by conflict.
+	Upsert(m Model, conflict OnConflict) *Query
+
+	// WithTransaction runs fn inside of a transaction. The transaction is
+	// committed when fn returns nil and rolled back when it returns an error
+	// or panics, in which case the panic is propagated after the rollback.
+	WithTransaction(fn func(Transaction) error, opts ...TransactionOption) error
+
+	// WithTransactionContext is like WithTransaction, but runs the
+	// transaction with the provided context.
+	WithTransactionContext(ctx context.Context, fn func(Transaction) error, opts ...TransactionOption) error
+}
+
+// Option configures optional behavior of a Database.
+type Option func(*options)
+
+type options struct {
+	statementCacheSize   int
+	listenURL            string
+	minReconnectInterval time.Duration
+	maxReconnectInterval time.Duration
+}
+
+// StatementCacheSize sets the maximum number of prepared statements that the
+// database keeps cached. When the cache is full, the least recently used
+// statement is closed to make room for a new one.
+func StatementCacheSize(size int) Option {
+	return func(o *options) {
+		o.statementCacheSize = size
+	}
+}
+
+// DisablePreparedStatements turns off the prepared statement cache and stops
+// kin from explicitly preparing statements before running them. This is
+// needed when connecting through a pooler such as PgBouncer in transaction
+// pooling mode, where named server-side prepared statements aren't allowed.
+// Such setups should also set binary_parameters=yes on the connection URL.
+func DisablePreparedStatements() Option {
+	return StatementCacheSize(0)
+}
+
+// ListenURL sets the connection URL that Listen uses to open its own
+// connection to the database. It's only needed by databases created with New,
+// since NewConnection already knows its URL.
+func ListenURL(url string) Option {
+	return func(o *options) {
+		o.listenURL = url
+	}
+}
+
+// ListenReconnectInterval sets how long a subscription waits before trying
+// to reconnect after losing its connection. The interval starts at min and
+// doubles after each failed attempt, up to max.
+func ListenReconnectInterval(min, max time.Duration) Option {
+	return func(o *options) {
+		o.minReconnectInterval = min
+		o.maxReconnectInterval = max
+	}
 }
 
 // New creates a new wrapper around an existing DB connection.
-func New(db *sql.DB) (Database, error) {
+func New(db *sql.DB, opts ...Option) (Database, error) {
 	if db == nil {
 		return nil, errors.New("db connection must be initialized")
 	}
@@ -34,65 +185,186 @@ func New(db *sql.DB) (Database, error) {
 		return nil, fmt.Errorf("unable to connect to database %v", err)
 	}
 
-	return &database{db: db}, nil
+	o := options{
+		statementCacheSize:   defaultStatementCacheSize,
+		minReconnectInterval: defaultMinReconnectInterval,
+		maxReconnectInterval: defaultMaxReconnectInterval,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	d := &database{db: db, opts: o}
+	if o.statementCacheSize > 0 {
+		d.stmts = newStmtCache(db, o.statementCacheSize)
+	}
+
+	return d, nil
 }
 
 // NewConnection initializes a new connection and creates a wrapper around it.
-func NewConnection(dbURL string) (Database, error) {
+func NewConnection(dbURL string, opts ...Option) (Database, error) {
 	db, err := sql.Open("postgres", dbURL)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create connection %v", err)
 	}
 
-	return New(db)
+	return New(db, append([]Option{ListenURL(dbURL)}, opts...)...)
 }
 
 type database struct {
-	db *sql.DB
+	db    *sql.DB
+	stmts *stmtCache
+	opts  options
 }
 
 func (d *database) Close() error {
+	if d.stmts != nil {
+		d.stmts.close()
+	}
+
 	return d.db.Close()
 }
 
-func (d *database) Insert(m Model) *Query {
-	var columns string
-	var values string
-	var params []interface{}
+func (d *database) CopyFrom(models []Model) (int64, error) {
+	return d.CopyFromContext(context.Background(), models)
+}
 
-	for _, column := range m.Columns() {
-		if !column.IsSet() {
-			continue
-		}
+func (d *database) CopyFromContext(ctx context.Context, models []Model) (int64, error) {
+	return copyFromDB(ctx, d.db, modelSlice(models))
+}
 
-		separator := ""
-		if len(params) > 0 {
-			separator = ", "
-		}
+func (d *database) CopyFromChan(models <-chan Model) (int64, error) {
+	return d.CopyFromChanContext(context.Background(), models)
+}
 
-		params = append(params, column.Get())
-		columns = fmt.Sprintf("%s%s%s", columns, separator, column.FieldName())
-		values = fmt.Sprintf("%s%s$%d", values, separator, len(params))
+func (d *database) CopyFromChanContext(ctx context.Context, models <-chan Model) (int64, error) {
+	return copyFromDB(ctx, d.db, modelChan(ctx, models))
+}
+
+func (d *database) CopyTo(w io.Writer, format CopyFormat, stmt string, params ...interface{}) (int64, error) {
+	return d.CopyToContext(context.Background(), w, format, stmt, params...)
+}
+
+func (d *database) CopyToContext(ctx context.Context, w io.Writer, format CopyFormat, stmt string, params ...interface{}) (int64, error) {
+	return copyTo(ctx, d.Query(stmt, params...), w, format)
+}
+
+func (d *database) Delete(m PrimaryKeyer) *Query {
+	stmt, params, err := deleteStmt(m)
+	return d.Query(stmt, params...).withErr(err)
+}
+
+func (d *database) Exec(query string, args ...interface{}) (*ExecResult, error) {
+	return d.ExecContext(context.Background(), query, args...)
+}
+
+func (d *database) ExecContext(ctx context.Context, query string, args ...interface{}) (*ExecResult, error) {
+	query, args, err := bindParams(query, args)
+	if err != nil {
+		return nil, err
 	}
 
-	stmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING *", m.TableName(), columns, values)
+	return exec(ctx, d.db, query, args...)
+}
+
+func (d *database) Find(m PrimaryKeyer, key interface{}) *Query {
+	stmt, params := findStmt(m, key)
+	return d.Query(stmt, params...)
+}
 
+func (d *database) Insert(m Model) *Query {
+	stmt, params := insertStmt(m)
 	return d.Query(stmt, params...)
 }
 
+func (d *database) InsertMany(models []Model) error {
+	return d.InsertManyContext(context.Background(), models)
+}
+
+func (d *database) InsertManyContext(ctx context.Context, models []Model) error {
+	chunks, err := insertManyChunks(models)
+	if err != nil {
+		return err
+	}
+
+	if len(chunks) <= 1 {
+		return insertMany(ctx, d.Query, chunks)
+	}
+
+	return d.WithTransactionContext(ctx, func(txn Transaction) error {
+		return insertMany(ctx, txn.Query, chunks)
+	})
+}
+
+func (d *database) Listen(channels ...string) (*Subscription, error) {
+	return listen(d.opts.listenURL, d.opts.minReconnectInterval, d.opts.maxReconnectInterval, channels)
+}
+
+func (d *database) NamedQuery(stmt string, params map[string]interface{}) *Query {
+	return namedQuery(d.Query, stmt, params)
+}
+
+func (d *database) NamedModelQuery(stmt string, m Model) *Query {
+	return namedModelQuery(d.Query, stmt, m)
+}
+
+func (d *database) Notify(channel, payload string) error {
+	return d.NotifyContext(context.Background(), channel, payload)
+}
+
+func (d *database) NotifyContext(ctx context.Context, channel, payload string) error {
+	return notify(ctx, d.ExecContext, channel, payload)
+}
+
 func (d *database) Query(stmt string, params ...interface{}) *Query {
 	return &Query{
 		db:     d.db,
+		stmts:  d.stmts,
 		stmt:   stmt,
 		params: params,
 	}
 }
 
+func (d *database) Update(m PrimaryKeyer) *Query {
+	stmt, params, err := updateStmt(m)
+	return d.Query(stmt, params...).withErr(err)
+}
+
+func (d *database) Upsert(m Model, conflict OnConflict) *Query {
+	stmt, params, err := upsertStmt(m, conflict)
+	return d.Query(stmt, params...).withErr(err)
+}
+
 func (d *database) StartTransaction() (Transaction, error) {
-	tx, err := d.db.Begin()
+	return d.StartTransactionContext(context.Background())
+}
+
+func (d *database) StartTransactionContext(ctx context.Context) (Transaction, error) {
+	return d.StartTransactionWithOptions(ctx, TxOptions{})
+}
+
+func (d *database) StartTransactionWithOptions(ctx context.Context, opts TxOptions) (Transaction, error) {
+	tx, err := d.db.BeginTx(ctx, &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(ctx, err)
+	}
+
+	if settings := opts.settings(); len(settings) > 0 {
+		if _, err := tx.ExecContext(ctx, strings.Join(settings, "; ")); err != nil {
+			tx.Rollback()
+			return nil, wrapError(ctx, err)
+		}
 	}
 
-	return &transaction{tx: tx}, nil
+	return newTransaction(tx, d.stmts), nil
+}
+
+func (d *database) WithTransaction(fn func(Transaction) error, opts ...TransactionOption) error {
+	return d.WithTransactionContext(context.Background(), fn, opts...)
+}
+
+func (d *database) WithTransactionContext(ctx context.Context, fn func(Transaction) error, opts ...TransactionOption) error {
+	return withTransaction(ctx, d.StartTransactionWithOptions, fn, opts...)
 }
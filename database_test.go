@@ -104,6 +104,10 @@ func (tm testModel) Columns() []FieldBuilder {
 	}
 }
 
+func (tm testModel) PrimaryKey() FieldBuilder {
+	return IntField("id", &tm.ID)
+}
+
 func TestInsert(t *testing.T) {
 	tm := testModel{ID: 1, Name: "Donkey Hote"}
 	db := &database{}
@@ -191,3 +195,168 @@ func TestInsertSetTime(t *testing.T) {
 		}
 	}
 }
+
+type testModelNull struct {
+	ID     int
+	Status *string
+	clear  bool
+}
+
+func (tm *testModelNull) TableName() string {
+	return "test_model_null"
+}
+
+func (tm *testModelNull) Columns() []FieldBuilder {
+	status := NullStringField("status", &tm.Status)
+	if tm.clear {
+		status = SetNull(status)
+	}
+
+	return []FieldBuilder{
+		IntField("id", &tm.ID),
+		status,
+	}
+}
+
+func TestInsertNullDefault(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, err := NewConnection(connStr)
+	if err != nil {
+		t.Errorf("NewConnection(%s) = %v, want <nil>", connStr, err)
+		return
+	}
+	defer db.Close()
+
+	txn, err := db.StartTransaction()
+	if err != nil {
+		t.Errorf("db.StartTransaction() = (_, %v), want (_, <nil>)", err)
+		return
+	}
+	defer txn.Rollback()
+
+	if _, err := txn.Exec("CREATE TEMP TABLE test_model_null (id int PRIMARY KEY, status text DEFAULT 'draft')"); err != nil {
+		t.Errorf("txn.Exec(CREATE TEMP TABLE ...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	tests := []struct {
+		model *testModelNull
+		want  *string
+	}{
+		{&testModelNull{ID: 1}, stringPtr("draft")},
+		{&testModelNull{ID: 2, clear: true}, nil},
+		{&testModelNull{ID: 3, Status: stringPtr("published")}, stringPtr("published")},
+	}
+
+	for _, test := range tests {
+		res, err := txn.Insert(test.model).One()
+		if err != nil {
+			t.Errorf("txn.Insert(%d).One() = (_, %v), want (_, <nil>)", test.model.ID, err)
+			continue
+		}
+
+		got := res.ExtractNullString("status")
+		if (got == nil) != (test.want == nil) || (got != nil && *got != *test.want) {
+			t.Errorf("txn.Insert(%d) status = %v, want %v", test.model.ID, got, test.want)
+		}
+	}
+}
+
+func TestNullFieldIsSet(t *testing.T) {
+	var status *string
+
+	if NullStringField("status", &status).IsSet() {
+		t.Errorf("NullStringField(nil).IsSet() = true, want false")
+	}
+
+	if !SetNull(NullStringField("status", &status)).IsSet() {
+		t.Errorf("SetNull(NullStringField(nil)).IsSet() = false, want true")
+	}
+
+	tm := &testModelNull{ID: 1}
+	wantStmt := "INSERT INTO test_model_null (id) VALUES ($1) RETURNING *"
+	if stmt, _ := insertStmt(tm); stmt != wantStmt {
+		t.Errorf("insertStmt(...) = %s, want %s", stmt, wantStmt)
+	}
+
+	tm.clear = true
+	wantStmt = "INSERT INTO test_model_null (id, status) VALUES ($1, $2) RETURNING *"
+	if stmt, params := insertStmt(tm); stmt != wantStmt || params[1] != nil {
+		t.Errorf("insertStmt(...) = (%s, %v), want (%s, [1 <nil>])", stmt, params, wantStmt)
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestUpdate(t *testing.T) {
+	tm := testModel{ID: 1, Name: "Donkey Hote"}
+	db := &database{}
+	q := db.Update(tm)
+
+	if q.err != nil {
+		t.Errorf("q.err = %v, want <nil>", q.err)
+		return
+	}
+
+	wantStmt := "UPDATE test_model SET name = $1 WHERE id = $2 RETURNING *"
+	if q.stmt != wantStmt {
+		t.Errorf("q.stmt = %s, want %s", q.stmt, wantStmt)
+	}
+
+	wantParams := []interface{}{"Donkey Hote", 1}
+	if len(q.params) != len(wantParams) {
+		t.Errorf("len(q.params) = %d, want %d", len(q.params), len(wantParams))
+		return
+	}
+
+	for i, param := range q.params {
+		if param != wantParams[i] {
+			t.Errorf("q.params[%d] = %v, want %v", i, param, wantParams[i])
+		}
+	}
+}
+
+func TestUpdateNoPrimaryKey(t *testing.T) {
+	tm := testModel{Name: "Donkey Hote"}
+	db := &database{}
+
+	if _, err := db.Update(tm).Run(); err != errPrimaryKeyNotSet {
+		t.Errorf("db.Update(...).Run() = (_, %v), want (_, %v)", err, errPrimaryKeyNotSet)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tm := testModel{ID: 1, Name: "Donkey Hote"}
+	db := &database{}
+	q := db.Delete(tm)
+
+	wantStmt := "DELETE FROM test_model WHERE id = $1 RETURNING *"
+	if q.stmt != wantStmt {
+		t.Errorf("q.stmt = %s, want %s", q.stmt, wantStmt)
+	}
+
+	if len(q.params) != 1 || q.params[0] != 1 {
+		t.Errorf("q.params = %v, want [1]", q.params)
+	}
+}
+
+func TestFind(t *testing.T) {
+	db := &database{}
+	q := db.Find(testModel{}, 5)
+
+	wantStmt := "SELECT * FROM test_model WHERE id = $1"
+	if q.stmt != wantStmt {
+		t.Errorf("q.stmt = %s, want %s", q.stmt, wantStmt)
+	}
+
+	if len(q.params) != 1 || q.params[0] != 5 {
+		t.Errorf("q.params = %v, want [5]", q.params)
+	}
+}
@@ -0,0 +1,173 @@
+package kin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrCanceled is returned when a database operation is interrupted because
+	// its context was canceled or its deadline expired. The returned error
+	// also wraps the underlying context error, so both
+	// errors.Is(err, ErrCanceled) and errors.Is(err, context.DeadlineExceeded)
+	// can be used to inspect it.
+	ErrCanceled = errors.New("database operation canceled")
+
+	// ErrNoRows is returned when a query that must return a row doesn't.
+	ErrNoRows = errors.New("no rows in result set")
+
+	// ErrTooManyRows is returned when a query that must return at most one
+	// row returns more.
+	ErrTooManyRows = errors.New("more than one row in result set")
+
+	// ErrEmptyIn is returned when a list marked with In is empty.
+	ErrEmptyIn = errors.New("list used with In is empty")
+
+	// ErrInvalidCursor is returned when a cursor passed to Paginate can't be
+	// decoded or doesn't match the query's sort keys.
+	ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+	// ErrUniqueViolation matches errors caused by a unique constraint
+	// violation (SQLSTATE 23505).
+	ErrUniqueViolation = errors.New("unique violation")
+
+	// ErrForeignKeyViolation matches errors caused by a foreign key constraint
+	// violation (SQLSTATE 23503).
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+
+	// ErrCheckViolation matches errors caused by a check constraint violation
+	// (SQLSTATE 23514).
+	ErrCheckViolation = errors.New("check violation")
+
+	// ErrNotNullViolation matches errors caused by a not null constraint
+	// violation (SQLSTATE 23502).
+	ErrNotNullViolation = errors.New("not null violation")
+
+	// ErrSerializationFailure matches errors caused by a transaction that
+	// couldn't be serialized with concurrent transactions (SQLSTATE 40001).
+	ErrSerializationFailure = errors.New("serialization failure")
+
+	// ErrDeadlock matches errors caused by a detected deadlock (SQLSTATE
+	// 40P01).
+	ErrDeadlock = errors.New("deadlock detected")
+)
+
+var errorCodes = map[string]error{
+	"23505": ErrUniqueViolation,
+	"23503": ErrForeignKeyViolation,
+	"23514": ErrCheckViolation,
+	"23502": ErrNotNullViolation,
+	"40001": ErrSerializationFailure,
+	"40P01": ErrDeadlock,
+}
+
+// Error is an error reported by Postgres. It can be matched against the
+// sentinel errors in this package with errors.Is, for example:
+//
+//	if errors.Is(err, kin.ErrUniqueViolation) {
+//		var pgErr *kin.Error
+//		errors.As(err, &pgErr)
+//		fmt.Println(pgErr.Constraint)
+//	}
+type Error struct {
+	// Code is the SQLSTATE code of the error.
+	Code string
+
+	// Message is the primary error message.
+	Message string
+
+	// Detail is an optional secondary message with more detail.
+	Detail string
+
+	// Schema is the name of the schema that the error is associated with.
+	Schema string
+
+	// Table is the name of the table that the error is associated with.
+	Table string
+
+	// Column is the name of the column that the error is associated with.
+	Column string
+
+	// Constraint is the name of the constraint that the error is associated
+	// with.
+	Constraint string
+
+	err *pq.Error
+}
+
+func newError(err *pq.Error) *Error {
+	return &Error{
+		Code:       string(err.Code),
+		Message:    err.Message,
+		Detail:     err.Detail,
+		Schema:     err.Schema,
+		Table:      err.Table,
+		Column:     err.Column,
+		Constraint: err.Constraint,
+		err:        err,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+// Is reports whether the error matches one of the sentinel errors in this
+// package based on its SQLSTATE code.
+func (e *Error) Is(target error) bool {
+	sentinel, ok := errorCodes[e.Code]
+	return ok && sentinel == target
+}
+
+// Unwrap returns the underlying driver error.
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+type canceledError struct {
+	ctxErr error
+	err    error
+}
+
+func (c canceledError) Error() string {
+	if c.err == nil {
+		return ErrCanceled.Error() + ": " + c.ctxErr.Error()
+	}
+
+	return ErrCanceled.Error() + ": " + c.ctxErr.Error() + ": " + c.err.Error()
+}
+
+func (c canceledError) Is(target error) bool {
+	return target == ErrCanceled
+}
+
+func (c canceledError) Unwrap() error {
+	return c.ctxErr
+}
+
+// wrapError converts an error returned by the driver into one of kin's
+// errors: a canceledError when the context that the operation ran with is no
+// longer active, or an Error when it was reported by Postgres.
+func wrapError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var kinErr *Error
+	var pqErr *pq.Error
+	if !errors.As(err, &kinErr) && errors.As(err, &pqErr) {
+		err = newError(pqErr)
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		if errors.Is(err, ctxErr) {
+			return canceledError{ctxErr: ctxErr}
+		}
+
+		return canceledError{ctxErr: ctxErr, err: err}
+	}
+
+	return err
+}
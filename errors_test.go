@@ -0,0 +1,64 @@
+package kin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestWrapErrorPostgres(t *testing.T) {
+	pqErr := &pq.Error{
+		Code:       "23505",
+		Message:    "duplicate key value violates unique constraint \"users_email_key\"",
+		Detail:     "Key (email)=(a@b.c) already exists.",
+		Table:      "users",
+		Constraint: "users_email_key",
+	}
+
+	err := fmt.Errorf("inserting user: %w", wrapError(context.Background(), pqErr))
+	if !errors.Is(err, ErrUniqueViolation) {
+		t.Errorf("errors.Is(%v, ErrUniqueViolation) = false, want true", err)
+	}
+
+	if errors.Is(err, ErrForeignKeyViolation) {
+		t.Errorf("errors.Is(%v, ErrForeignKeyViolation) = true, want false", err)
+	}
+
+	var kinErr *Error
+	if !errors.As(err, &kinErr) {
+		t.Errorf("errors.As(%v, *Error) = false, want true", err)
+		return
+	}
+
+	if kinErr.Code != "23505" || kinErr.Table != "users" || kinErr.Constraint != "users_email_key" || kinErr.Detail != pqErr.Detail {
+		t.Errorf("kinErr = %+v, want fields copied from %+v", kinErr, pqErr)
+	}
+
+	var driverErr *pq.Error
+	if !errors.As(err, &driverErr) || driverErr != pqErr {
+		t.Errorf("errors.As(%v, *pq.Error) = %v, want %v", err, driverErr, pqErr)
+	}
+}
+
+func TestWrapErrorCodes(t *testing.T) {
+	tests := []struct {
+		code string
+		want error
+	}{
+		{"23503", ErrForeignKeyViolation},
+		{"23514", ErrCheckViolation},
+		{"23502", ErrNotNullViolation},
+		{"40001", ErrSerializationFailure},
+		{"40P01", ErrDeadlock},
+	}
+
+	for _, test := range tests {
+		err := wrapError(context.Background(), &pq.Error{Code: pq.ErrorCode(test.code)})
+		if !errors.Is(err, test.want) {
+			t.Errorf("errors.Is(wrapError(%s), %v) = false, want true", test.code, test.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package kin
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ExecResult describes the outcome of a statement that was executed without
+// reading any returned rows.
+type ExecResult struct {
+	// Command is the SQL command that was executed, such as INSERT, UPDATE or
+	// DELETE. It's taken from the first keyword of the statement, or for
+	// statements that start with a WITH clause, from the main statement that
+	// follows it.
+	Command string
+
+	// RowsAffected is the number of rows inserted, updated or deleted by the
+	// statement.
+	RowsAffected int64
+}
+
+func newExecResult(stmt string, res sql.Result) *ExecResult {
+	// Statements that don't affect rows, such as DDL, report zero.
+	rowsAffected, _ := res.RowsAffected()
+
+	return &ExecResult{
+		Command:      command(stmt),
+		RowsAffected: rowsAffected,
+	}
+}
+
+// requireRows returns ErrNoRows when an insert, update or delete didn't
+// affect any rows.
+func (r *ExecResult) requireRows() error {
+	switch r.Command {
+	case "INSERT", "UPDATE", "DELETE":
+		if r.RowsAffected == 0 {
+			return ErrNoRows
+		}
+	}
+
+	return nil
+}
+
+// exec runs a statement without preparing it, which allows statements that
+// contain multiple commands when there are no arguments.
+func exec(ctx context.Context, db databaseConnection, stmt string, args ...interface{}) (*ExecResult, error) {
+	res, err := db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, wrapError(ctx, err)
+	}
+
+	return newExecResult(stmt, res), nil
+}
+
+// command returns the statement's command in upper case. It's the first
+// keyword of the statement, skipping any leading whitespace and comments, or
+// for statements that start with a WITH clause, the first command that follows
+// the clause's common table expressions.
+func command(stmt string) string {
+	token, rest := sqlToken(stmt)
+	if token != "WITH" {
+		if !isWord(token) {
+			return ""
+		}
+		return token
+	}
+
+	// The common table expressions are parenthesized, so the main statement
+	// is the first command outside of parentheses after one of them.
+	depth := 0
+	queried := false
+	for {
+		token, rest = sqlToken(rest)
+		switch token {
+		case "":
+			return "WITH"
+		case "(":
+			depth++
+		case ")":
+			depth--
+			queried = queried || depth == 0
+		case "SELECT", "INSERT", "UPDATE", "DELETE", "VALUES", "TABLE", "MERGE":
+			if depth == 0 && queried {
+				return token
+			}
+		}
+	}
+}
+
+// sqlToken returns the next token of the statement and the rest of it, skipping
+// whitespace and comments. Words are returned in upper case, quoted strings and
+// identifiers are returned whole, and anything else is returned a character at a
+// time. It returns an empty token at the end of the statement.
+func sqlToken(stmt string) (string, string) {
+	for {
+		stmt = strings.TrimLeftFunc(stmt, unicode.IsSpace)
+		switch {
+		case stmt == "":
+			return "", ""
+		case strings.HasPrefix(stmt, "--"):
+			end := strings.IndexByte(stmt, '\n')
+			if end < 0 {
+				return "", ""
+			}
+			stmt = stmt[end:]
+		case strings.HasPrefix(stmt, "/*"):
+			end := strings.Index(stmt, "*/")
+			if end < 0 {
+				return "", ""
+			}
+			stmt = stmt[end+2:]
+		case stmt[0] == '\'' || stmt[0] == '"':
+			end := strings.IndexByte(stmt[1:], stmt[0])
+			if end < 0 {
+				return "", ""
+			}
+			return stmt[:end+2], stmt[end+2:]
+		case isWord(stmt):
+			end := strings.IndexFunc(stmt, func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+			})
+			if end < 0 {
+				end = len(stmt)
+			}
+			return strings.ToUpper(stmt[:end]), stmt[end:]
+		default:
+			_, size := utf8.DecodeRuneInString(stmt)
+			return stmt[:size], stmt[size:]
+		}
+	}
+}
+
+// isWord reports whether the token is a keyword or an unquoted identifier.
+func isWord(token string) bool {
+	r, _ := utf8.DecodeRuneInString(token)
+	return unicode.IsLetter(r) || r == '_'
+}
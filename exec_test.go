@@ -0,0 +1,102 @@
+package kin
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	_ "github.com/jmataya/renv/autoload"
+)
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want string
+	}{
+		{"UPDATE users SET name = $1", "UPDATE"},
+		{"  delete from users", "DELETE"},
+		{"-- remove a user\nDELETE FROM users", "DELETE"},
+		{"/* bulk */ insert into users (name) values ($1)", "INSERT"},
+		{"WITH moved AS (DELETE FROM a RETURNING *) INSERT INTO b SELECT * FROM moved", "INSERT"},
+		{"with recursive t(n) as (select 1 union all select n + 1 from t), stale as (select id from users) update users set active = false where id in (select id from stale)", "UPDATE"},
+		{"WITH \"select\" AS MATERIALIZED (SELECT ')' AS paren) DELETE FROM users USING \"select\"", "DELETE"},
+		{"WITH unfinished AS (SELECT 1", "WITH"},
+		{"VACUUM", "VACUUM"},
+		{"-- only a comment", ""},
+	}
+
+	for _, test := range tests {
+		if got := command(test.stmt); got != test.want {
+			t.Errorf("command(%q) = %s, want %s", test.stmt, got, test.want)
+		}
+	}
+}
+
+func TestExecResultRequireRows(t *testing.T) {
+	tests := []struct {
+		res  ExecResult
+		want error
+	}{
+		{ExecResult{Command: "UPDATE", RowsAffected: 0}, ErrNoRows},
+		{ExecResult{Command: "DELETE", RowsAffected: 0}, ErrNoRows},
+		{ExecResult{Command: "UPDATE", RowsAffected: 2}, nil},
+		{ExecResult{Command: "CREATE", RowsAffected: 0}, nil},
+		{ExecResult{Command: command("WITH t AS (SELECT 1) UPDATE users SET name = 'x'"), RowsAffected: 0}, ErrNoRows},
+	}
+
+	for _, test := range tests {
+		if got := test.res.requireRows(); got != test.want {
+			t.Errorf("%+v.requireRows() = %v, want %v", test.res, got, test.want)
+		}
+	}
+}
+
+func TestDatabaseExec(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, err := NewConnection(connStr)
+	if err != nil {
+		t.Errorf("NewConnection(%s) = %v, want <nil>", connStr, err)
+		return
+	}
+	defer db.Close()
+
+	txn, err := db.StartTransaction()
+	if err != nil {
+		t.Errorf("db.StartTransaction() = (_, %v), want (_, <nil>)", err)
+		return
+	}
+	defer txn.Rollback()
+
+	if _, err := txn.Exec("CREATE TEMP TABLE execs (id int); INSERT INTO execs VALUES (1), (2)"); err != nil {
+		t.Errorf("txn.Exec(...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	res, err := txn.Exec("UPDATE execs SET id = id + 10")
+	if err != nil {
+		t.Errorf("txn.Exec(...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	if res.Command != "UPDATE" || res.RowsAffected != 2 {
+		t.Errorf("txn.Exec(...) = (%+v, _), want ({UPDATE 2}, _)", res)
+	}
+
+	if _, err := txn.Query("DELETE FROM execs WHERE id = $1", 1).ExecRequireRows(); err != ErrNoRows {
+		t.Errorf("txn.Query(...).ExecRequireRows() = (_, %v), want (_, %v)", err, ErrNoRows)
+	}
+
+	ctx := context.Background()
+	if res, err := txn.Query("DELETE FROM execs WHERE id = $1", 11).ExecRequireRowsContext(ctx); err != nil || res.RowsAffected != 1 {
+		t.Errorf("txn.Query(...).ExecRequireRowsContext(...) = (%+v, %v), want ({DELETE 1}, <nil>)", res, err)
+	}
+
+	if res, err := txn.Query("DELETE FROM execs").ExecContext(ctx); err != nil || res.RowsAffected != 1 {
+		t.Errorf("txn.Query(...).ExecContext(...) = (%+v, %v), want ({DELETE 1}, <nil>)", res, err)
+	}
+}
@@ -1,6 +1,8 @@
 package kin
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"time"
 )
 
@@ -125,6 +127,37 @@ func (j jsonField) Set(res *RowResult) {
 	res.ExtractJSON(j.fieldName, j.field)
 }
 
+// paramValue returns the value of a field to send to the database. JSON fields
+// return the value that they wrap from Get, so they're marshalled here.
+func paramValue(field FieldBuilder) interface{} {
+	if f, ok := field.(setNullField); ok {
+		field = f.FieldBuilder
+	}
+
+	switch f := field.(type) {
+	case jsonField:
+		return jsonValue{f.field}
+	case nullJSONField:
+		return nullJSONValue{f.field}
+	default:
+		return field.Get()
+	}
+}
+
+// jsonValue marshals a field to JSON when it's sent to the database.
+type jsonValue struct {
+	field interface{}
+}
+
+func (j jsonValue) Value() (driver.Value, error) {
+	b, err := json.Marshal(j.field)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
 // StringField creates a reference to a string field.
 func StringField(fieldName string, field *string) FieldBuilder {
 	return stringField{fieldName, field}
@@ -176,3 +209,236 @@ func (t timeField) IsSet() bool {
 func (t timeField) Set(res *RowResult) {
 	*t.field = res.ExtractTime(t.fieldName)
 }
+
+// NullBoolField creates a reference to a boolean field that may be NULL. A
+// NULL column is read as nil. Like the zero value of other fields, a nil value
+// isn't set, so it's left out of inserts and updates and the column keeps its
+// default. Wrap the field with SetNull to write NULL.
+func NullBoolField(fieldName string, field **bool) FieldBuilder {
+	return nullBoolField{fieldName, field}
+}
+
+type nullBoolField struct {
+	fieldName string
+	field     **bool
+}
+
+func (b nullBoolField) FieldName() string {
+	return b.fieldName
+}
+
+func (b nullBoolField) Get() interface{} {
+	if *b.field == nil {
+		return nil
+	}
+
+	return **b.field
+}
+
+func (b nullBoolField) IsSet() bool {
+	return *b.field != nil
+}
+
+func (b nullBoolField) Set(res *RowResult) {
+	*b.field = res.ExtractNullBool(b.fieldName)
+}
+
+// NullDecimalField creates a reference to a field for a floating point number
+// that may be NULL. It treats nil like NullBoolField does.
+func NullDecimalField(fieldName string, field **float64) FieldBuilder {
+	return nullDecimalField{fieldName, field}
+}
+
+type nullDecimalField struct {
+	fieldName string
+	field     **float64
+}
+
+func (d nullDecimalField) FieldName() string {
+	return d.fieldName
+}
+
+func (d nullDecimalField) Get() interface{} {
+	if *d.field == nil {
+		return nil
+	}
+
+	return **d.field
+}
+
+func (d nullDecimalField) IsSet() bool {
+	return *d.field != nil
+}
+
+func (d nullDecimalField) Set(res *RowResult) {
+	*d.field = res.ExtractNullDecimal(d.fieldName)
+}
+
+// NullIntField creates a reference to an integer field that may be NULL. It
+// treats nil like NullBoolField does.
+func NullIntField(fieldName string, field **int) FieldBuilder {
+	return nullIntField{fieldName, field}
+}
+
+type nullIntField struct {
+	fieldName string
+	field     **int
+}
+
+func (i nullIntField) FieldName() string {
+	return i.fieldName
+}
+
+func (i nullIntField) Get() interface{} {
+	if *i.field == nil {
+		return nil
+	}
+
+	return **i.field
+}
+
+func (i nullIntField) IsSet() bool {
+	return *i.field != nil
+}
+
+func (i nullIntField) Set(res *RowResult) {
+	*i.field = res.ExtractNullInt(i.fieldName)
+}
+
+// NullJSONField creates a reference to a JSON field that may be NULL. The
+// field isn't set when it marshals to JSON null, such as a nil pointer, map or
+// slice, and is written as NULL when it's wrapped with SetNull. When the
+// column is NULL, the field is unmarshalled from JSON null, which sets
+// pointers, maps and slices to nil.
+func NullJSONField(fieldName string, field interface{}) FieldBuilder {
+	return nullJSONField{fieldName, field}
+}
+
+type nullJSONField struct {
+	fieldName string
+	field     interface{}
+}
+
+func (j nullJSONField) FieldName() string {
+	return j.fieldName
+}
+
+func (j nullJSONField) Get() interface{} {
+	return j.field
+}
+
+func (j nullJSONField) IsSet() bool {
+	b, err := json.Marshal(j.field)
+
+	// A field that can't be marshalled is set, so that the error is reported
+	// when it's written.
+	return err != nil || string(b) != "null"
+}
+
+func (j nullJSONField) Set(res *RowResult) {
+	res.ExtractNullJSON(j.fieldName, j.field)
+}
+
+// nullJSONValue marshals a field to JSON when it's sent to the database,
+// sending NULL in place of JSON null.
+type nullJSONValue struct {
+	field interface{}
+}
+
+func (j nullJSONValue) Value() (driver.Value, error) {
+	b, err := json.Marshal(j.field)
+	if err != nil {
+		return nil, err
+	}
+
+	if string(b) == "null" {
+		return nil, nil
+	}
+
+	return string(b), nil
+}
+
+// NullStringField creates a reference to a string field that may be NULL. It
+// treats nil like NullBoolField does.
+func NullStringField(fieldName string, field **string) FieldBuilder {
+	return nullStringField{fieldName, field}
+}
+
+type nullStringField struct {
+	fieldName string
+	field     **string
+}
+
+func (s nullStringField) FieldName() string {
+	return s.fieldName
+}
+
+func (s nullStringField) Get() interface{} {
+	if *s.field == nil {
+		return nil
+	}
+
+	return **s.field
+}
+
+func (s nullStringField) IsSet() bool {
+	return *s.field != nil
+}
+
+func (s nullStringField) Set(res *RowResult) {
+	*s.field = res.ExtractNullString(s.fieldName)
+}
+
+// NullTimeField creates a reference to a time field that may be NULL. It
+// treats nil like NullBoolField does.
+func NullTimeField(fieldName string, field **time.Time) FieldBuilder {
+	return nullTimeField{fieldName, field}
+}
+
+type nullTimeField struct {
+	fieldName string
+	field     **time.Time
+}
+
+func (t nullTimeField) FieldName() string {
+	return t.fieldName
+}
+
+func (t nullTimeField) Get() interface{} {
+	if *t.field == nil {
+		return nil
+	}
+
+	return **t.field
+}
+
+func (t nullTimeField) IsSet() bool {
+	return *t.field != nil
+}
+
+func (t nullTimeField) Set(res *RowResult) {
+	*t.field = res.ExtractNullTime(t.fieldName)
+}
+
+// SetNull wraps a field so that it's always set, which writes NULL to the
+// column when the field is nil instead of leaving the column out. It's meant
+// for the nullable fields, to clear a column or to insert NULL in place of the
+// column's default:
+//
+//	func (p *post) Columns() []kin.FieldBuilder {
+//		return []kin.FieldBuilder{
+//			kin.IntField("id", &p.ID),
+//			kin.SetNull(kin.NullTimeField("published_at", &p.PublishedAt)),
+//		}
+//	}
+func SetNull(field FieldBuilder) FieldBuilder {
+	return setNullField{field}
+}
+
+type setNullField struct {
+	FieldBuilder
+}
+
+func (s setNullField) IsSet() bool {
+	return true
+}
@@ -0,0 +1,114 @@
+package kin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// maxParams is the maximum number of parameters that Postgres accepts in a
+// single statement.
+const maxParams = 65535
+
+// insertChunk is a single multi-row insert statement and the models that it
+// inserts.
+type insertChunk struct {
+	stmt   string
+	params []interface{}
+	models []Model
+}
+
+// insertManyChunks generates multi-row inserts for the models, split into as
+// many statements as needed to stay under the parameter limit. Each statement
+// inserts the same set of columns: every column that's set in at least one of
+// the models. Models that don't set one of those columns insert its default.
+func insertManyChunks(models []Model) ([]insertChunk, error) {
+	if len(models) == 0 {
+		return nil, nil
+	}
+
+	table := models[0].TableName()
+	var columns []string
+	for _, m := range models {
+		if m.TableName() != table {
+			return nil, fmt.Errorf("models must all belong to %s, found %s", table, m.TableName())
+		}
+
+		for _, column := range m.Columns() {
+			if column.IsSet() && !contains(columns, column.FieldName()) {
+				columns = append(columns, column.FieldName())
+			}
+		}
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns set to insert in %s", table)
+	}
+
+	rowsPerStmt := maxParams / len(columns)
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", table, strings.Join(columns, ", "))
+
+	var chunks []insertChunk
+	for start := 0; start < len(models); start += rowsPerStmt {
+		end := start + rowsPerStmt
+		if end > len(models) {
+			end = len(models)
+		}
+
+		var params []interface{}
+		rows := make([]string, 0, end-start)
+		for _, m := range models[start:end] {
+			set := map[string]FieldBuilder{}
+			for _, column := range m.Columns() {
+				if column.IsSet() {
+					set[column.FieldName()] = column
+				}
+			}
+
+			values := make([]string, len(columns))
+			for i, name := range columns {
+				column, ok := set[name]
+				if !ok {
+					values[i] = "DEFAULT"
+					continue
+				}
+
+				params = append(params, paramValue(column))
+				values[i] = fmt.Sprintf("$%d", len(params))
+			}
+
+			rows = append(rows, fmt.Sprintf("(%s)", strings.Join(values, ", ")))
+		}
+
+		chunks = append(chunks, insertChunk{
+			stmt:   prefix + strings.Join(rows, ", ") + " RETURNING *",
+			params: params,
+			models: models[start:end],
+		})
+	}
+
+	return chunks, nil
+}
+
+// insertMany runs the inserts and extracts the returned rows back into the
+// models in order.
+func insertMany(ctx context.Context, query func(string, ...interface{}) *Query, chunks []insertChunk) error {
+	for _, chunk := range chunks {
+		res, err := query(chunk.stmt, chunk.params...).RunContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(res.Rows) != len(chunk.models) {
+			return fmt.Errorf("insert returned %d rows, want %d", len(res.Rows), len(chunk.models))
+		}
+
+		for i, row := range res.Rows {
+			if err := buildOne(chunk.models[i], row); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
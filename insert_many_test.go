@@ -0,0 +1,208 @@
+package kin
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestInsertManyChunks(t *testing.T) {
+	models := []Model{
+		testModelTime{Name: "Donkey Hote"},
+		testModelTime{ID: 7, Name: "Sancho Panza"},
+	}
+
+	chunks, err := insertManyChunks(models)
+	if err != nil {
+		t.Errorf("insertManyChunks(...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	if len(chunks) != 1 {
+		t.Errorf("len(chunks) = %d, want 1", len(chunks))
+		return
+	}
+
+	wantStmt := "INSERT INTO test_model (name, id) VALUES ($1, DEFAULT), ($2, $3) RETURNING *"
+	if chunks[0].stmt != wantStmt {
+		t.Errorf("chunks[0].stmt = %s, want %s", chunks[0].stmt, wantStmt)
+	}
+
+	wantParams := []interface{}{"Donkey Hote", "Sancho Panza", 7}
+	if len(chunks[0].params) != len(wantParams) {
+		t.Errorf("len(chunks[0].params) = %d, want %d", len(chunks[0].params), len(wantParams))
+		return
+	}
+
+	for i, param := range chunks[0].params {
+		if param != wantParams[i] {
+			t.Errorf("chunks[0].params[%d] = %v, want %v", i, param, wantParams[i])
+		}
+	}
+}
+
+func TestInsertManyChunksParamLimit(t *testing.T) {
+	count := maxParams/2 + 1
+	models := make([]Model, count)
+	for i := range models {
+		models[i] = testModel{ID: i + 1, Name: "Donkey Hote"}
+	}
+
+	chunks, err := insertManyChunks(models)
+	if err != nil {
+		t.Errorf("insertManyChunks(...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	if len(chunks) != 2 {
+		t.Errorf("len(chunks) = %d, want 2", len(chunks))
+		return
+	}
+
+	inserted := 0
+	for i, chunk := range chunks {
+		if len(chunk.params) > maxParams {
+			t.Errorf("len(chunks[%d].params) = %d, want <= %d", i, len(chunk.params), maxParams)
+		}
+		inserted += len(chunk.models)
+	}
+
+	if inserted != count {
+		t.Errorf("chunks insert %d models, want %d", inserted, count)
+	}
+}
+
+func TestInsertManyChunksMixedTables(t *testing.T) {
+	models := []Model{
+		testModel{Name: "Donkey Hote"},
+		otherTestModel{},
+	}
+
+	if _, err := insertManyChunks(models); err == nil {
+		t.Error("insertManyChunks(...) = (_, <nil>), want (_, error)")
+	}
+}
+
+type otherTestModel struct{}
+
+func (otherTestModel) TableName() string {
+	return "other_test_model"
+}
+
+func (otherTestModel) Columns() []FieldBuilder {
+	return []FieldBuilder{}
+}
+
+type testInsertManyModel struct {
+	ID     int
+	Name   string
+	Status *string
+}
+
+func (tm *testInsertManyModel) TableName() string {
+	return "test_insert_many"
+}
+
+func (tm *testInsertManyModel) Columns() []FieldBuilder {
+	return []FieldBuilder{
+		IntField("id", &tm.ID),
+		StringField("name", &tm.Name),
+		NullStringField("status", &tm.Status),
+	}
+}
+
+func TestInsertMany(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, err := NewConnection(connStr)
+	if err != nil {
+		t.Errorf("NewConnection(%s) = %v, want <nil>", connStr, err)
+		return
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("DROP TABLE IF EXISTS test_insert_many; CREATE TABLE test_insert_many (id serial PRIMARY KEY, name text NOT NULL, status text NOT NULL DEFAULT 'new')"); err != nil {
+		t.Errorf("db.Exec(CREATE TABLE test_insert_many ...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+	defer db.Exec("DROP TABLE test_insert_many")
+
+	// Two columns are set, so the models don't fit in a single statement.
+	models := newInsertManyModels(maxParams/2 + 2)
+
+	if chunks, _ := insertManyChunks(models); len(chunks) != 2 {
+		t.Errorf("len(insertManyChunks(...)) = %d, want 2", len(chunks))
+		return
+	}
+
+	if err := db.InsertMany(models); err != nil {
+		t.Errorf("db.InsertMany(...) = %v, want <nil>", err)
+		return
+	}
+
+	checkInsertedModels(t, models)
+
+	txn, err := db.StartTransaction()
+	if err != nil {
+		t.Errorf("db.StartTransaction() = (_, %v), want (_, <nil>)", err)
+		return
+	}
+	defer txn.Rollback()
+
+	models = newInsertManyModels(3)
+
+	if err := txn.InsertMany(models); err != nil {
+		t.Errorf("txn.InsertMany(...) = %v, want <nil>", err)
+		return
+	}
+
+	checkInsertedModels(t, models)
+}
+
+// newInsertManyModels returns n models, where every other one sets its status.
+func newInsertManyModels(n int) []Model {
+	active := "active"
+	models := make([]Model, n)
+	for i := range models {
+		m := &testInsertManyModel{Name: fmt.Sprintf("model %d", i)}
+		if i%2 == 1 {
+			m.Status = &active
+		}
+		models[i] = m
+	}
+
+	return models
+}
+
+// checkInsertedModels checks that each model got back its own row, with the
+// generated id and the default status of models that didn't set one.
+func checkInsertedModels(t *testing.T, models []Model) {
+	lastID := 0
+	for i, model := range models {
+		m := model.(*testInsertManyModel)
+		if want := fmt.Sprintf("model %d", i); m.Name != want {
+			t.Errorf("models[%d].Name = %q, want %q", i, m.Name, want)
+			return
+		}
+
+		if m.ID <= lastID {
+			t.Errorf("models[%d].ID = %d, want more than %d", i, m.ID, lastID)
+			return
+		}
+		lastID = m.ID
+
+		want := "new"
+		if i%2 == 1 {
+			want = "active"
+		}
+
+		if m.Status == nil || *m.Status != want {
+			t.Errorf("models[%d].Status = %v, want %s", i, m.Status, want)
+			return
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package kin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	defaultMinReconnectInterval = time.Second
+	defaultMaxReconnectInterval = time.Minute
+)
+
+// Notification is a notification received on a channel that a subscription
+// is listening to.
+type Notification struct {
+	// Channel is the name of the channel that the notification was sent on.
+	Channel string
+
+	// Payload is the notification's payload, or an empty string if it was
+	// sent without one.
+	Payload string
+
+	// PID is the process ID of the server backend that sent the
+	// notification.
+	PID int
+}
+
+// Decode unmarshals the notification's payload as JSON into out.
+func (n *Notification) Decode(out interface{}) error {
+	if err := json.Unmarshal([]byte(n.Payload), out); err != nil {
+		return fmt.Errorf("notification on channel %s could not be decoded with error %v", n.Channel, err)
+	}
+
+	return nil
+}
+
+// Subscription receives notifications sent with NOTIFY on the channels that
+// it's listening to. It holds a dedicated connection to the database, which
+// is reestablished automatically if it's lost, after which the subscription
+// listens to its channels again. Notifications sent while the connection is
+// down are lost, so Reconnected reports when that happens.
+//
+//	sub, err := db.Listen("jobs")
+//	if err != nil {
+//		return err
+//	}
+//	defer sub.Close()
+//
+//	for {
+//		select {
+//		case n := <-sub.Notifications():
+//			var j job
+//			if err := n.Decode(&j); err != nil {
+//				return err
+//			}
+//		case <-sub.Reconnected():
+//			// Look for jobs that were queued while disconnected.
+//		}
+//	}
+type Subscription struct {
+	listener      *pq.Listener
+	notifications chan *Notification
+	reconnected   chan struct{}
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+// Notifications returns the channel that notifications are delivered on. It's
+// closed when the subscription is closed.
+func (s *Subscription) Notifications() <-chan *Notification {
+	return s.notifications
+}
+
+// Reconnected returns a channel that receives a value each time the
+// subscription's connection is reestablished after being lost. Notifications
+// sent while the connection was down are lost, so this is the time to catch up
+// on anything that was missed. Reconnections that happen before the value is
+// received are reported once. The channel is never closed.
+func (s *Subscription) Reconnected() <-chan struct{} {
+	return s.reconnected
+}
+
+// Listen starts listening to more channels.
+func (s *Subscription) Listen(channels ...string) error {
+	for _, channel := range channels {
+		if err := s.listener.Listen(channel); err != nil && err != pq.ErrChannelAlreadyOpen {
+			return wrapError(context.Background(), err)
+		}
+	}
+
+	return nil
+}
+
+// Unlisten stops listening to the channels.
+func (s *Subscription) Unlisten(channels ...string) error {
+	for _, channel := range channels {
+		if err := s.listener.Unlisten(channel); err != nil && err != pq.ErrChannelNotOpen {
+			return wrapError(context.Background(), err)
+		}
+	}
+
+	return nil
+}
+
+// Close stops listening to all channels and closes the subscription's
+// connection. It's safe to call multiple times.
+func (s *Subscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.listener.Close()
+	})
+
+	return err
+}
+
+// forward delivers the listener's notifications until the subscription is
+// closed. The listener sends nil after it reconnects, which is reported on the
+// reconnected channel without waiting for it to be read.
+func (s *Subscription) forward() {
+	defer close(s.notifications)
+
+	for n := range s.listener.Notify {
+		if n == nil {
+			select {
+			case s.reconnected <- struct{}{}:
+			default:
+			}
+			continue
+		}
+
+		select {
+		case s.notifications <- &Notification{Channel: n.Channel, Payload: n.Extra, PID: n.BePid}:
+		case <-s.done:
+			// Drain the listener so that it can shut down.
+			for range s.listener.Notify {
+			}
+			return
+		}
+	}
+}
+
+// listen opens a subscription to the channels on a new connection to the
+// database at url. It waits until the connection is established, so that an
+// unreachable database is reported instead of being retried forever.
+func listen(url string, minReconnect, maxReconnect time.Duration, channels []string) (*Subscription, error) {
+	if url == "" {
+		return nil, errors.New("listening requires a connection URL, set with NewConnection or ListenURL")
+	}
+
+	var once sync.Once
+	connected := make(chan error, 1)
+	listener := pq.NewListener(url, minReconnect, maxReconnect, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventConnected:
+			once.Do(func() { connected <- nil })
+		case pq.ListenerEventConnectionAttemptFailed:
+			once.Do(func() { connected <- err })
+		}
+	})
+
+	if err := <-connected; err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("unable to connect to database %v", err)
+	}
+
+	s := &Subscription{
+		listener:      listener,
+		notifications: make(chan *Notification),
+		reconnected:   make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+	go s.forward()
+
+	if err := s.Listen(channels...); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// notify sends a notification with pg_notify, which unlike NOTIFY accepts the
+// channel and payload as parameters.
+func notify(ctx context.Context, exec func(context.Context, string, ...interface{}) (*ExecResult, error), channel, payload string) error {
+	_, err := exec(ctx, "SELECT pg_notify($1, $2)", channel, payload)
+	return err
+}
@@ -0,0 +1,125 @@
+package kin
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/jmataya/renv/autoload"
+	"github.com/lib/pq"
+)
+
+func TestListen(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, err := NewConnection(connStr)
+	if err != nil {
+		t.Errorf("NewConnection(%s) = %v, want <nil>", connStr, err)
+		return
+	}
+	defer db.Close()
+
+	sub, err := db.Listen("kin_test")
+	if err != nil {
+		t.Errorf("db.Listen(...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+	defer sub.Close()
+
+	txn, err := db.StartTransaction()
+	if err != nil {
+		t.Errorf("db.StartTransaction() = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	if err := txn.Notify("kin_test", `{"id": 1}`); err != nil {
+		t.Errorf("txn.Notify(...) = %v, want <nil>", err)
+	}
+
+	select {
+	case n := <-sub.Notifications():
+		t.Errorf("received %v before commit, want nothing", n)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := txn.Commit(); err != nil {
+		t.Errorf("txn.Commit() = %v, want <nil>", err)
+	}
+
+	select {
+	case n := <-sub.Notifications():
+		var payload struct {
+			ID int `json:"id"`
+		}
+
+		if err := n.Decode(&payload); err != nil || payload.ID != 1 {
+			t.Errorf("n.Decode(...) = %v with ID %d, want <nil> with ID 1", err, payload.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("no notification received after commit")
+	}
+
+	if err := sub.Close(); err != nil {
+		t.Errorf("sub.Close() = %v, want <nil>", err)
+	}
+
+	if _, ok := <-sub.Notifications(); ok {
+		t.Error("sub.Notifications() is open after Close, want closed")
+	}
+}
+
+func TestListenWithoutURL(t *testing.T) {
+	db := &database{}
+	if _, err := db.Listen("kin_test"); err == nil {
+		t.Error("db.Listen(...) = (_, <nil>), want (_, error)")
+	}
+}
+
+func TestNotificationDecode(t *testing.T) {
+	var out map[string]int
+	n := &Notification{Channel: "kin_test", Payload: "not json"}
+	if err := n.Decode(&out); err == nil {
+		t.Errorf("n.Decode(...) = <nil>, want error")
+	}
+}
+
+func TestSubscriptionReconnected(t *testing.T) {
+	notify := make(chan *pq.Notification)
+	s := &Subscription{
+		listener:      &pq.Listener{Notify: notify},
+		notifications: make(chan *Notification),
+		reconnected:   make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+	go s.forward()
+
+	// Reconnections that aren't received yet are reported once.
+	notify <- nil
+	notify <- nil
+	notify <- &pq.Notification{Channel: "kin_test", Extra: "payload"}
+
+	if n := <-s.Notifications(); n.Payload != "payload" {
+		t.Errorf("<-s.Notifications() = %+v, want payload", n)
+	}
+
+	select {
+	case <-s.Reconnected():
+	default:
+		t.Error("s.Reconnected() is empty, want a reconnection")
+	}
+
+	select {
+	case <-s.Reconnected():
+		t.Error("s.Reconnected() has a second reconnection, want one")
+	default:
+	}
+
+	close(notify)
+	if _, ok := <-s.Notifications(); ok {
+		t.Error("s.Notifications() is open, want closed")
+	}
+}
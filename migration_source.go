@@ -0,0 +1,119 @@
+package kin
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+)
+
+// MigrationFile is a migration file, named as described by Migrator.
+type MigrationFile struct {
+	Name string
+	SQL  string
+}
+
+// MigrationSource provides the migration files that a Migrator runs. Files
+// that aren't SQL files are ignored.
+type MigrationSource interface {
+	// MigrationFiles returns the source's migration files.
+	MigrationFiles() ([]MigrationFile, error)
+}
+
+// MemorySource is a MigrationSource that holds its files in memory, which is
+// useful in tests.
+type MemorySource []MigrationFile
+
+// MigrationFiles returns the source's files.
+func (s MemorySource) MigrationFiles() ([]MigrationFile, error) {
+	return s, nil
+}
+
+// DirSource returns a MigrationSource that reads the migrations in a folder
+// on the operating system's file system.
+func DirSource(folderPath string) MigrationSource {
+	return fsSource{fsys: os.DirFS(folderPath), dir: ".", name: folderPath}
+}
+
+// FSSource returns a MigrationSource that reads the migrations in a directory
+// of the file system. It can be used with embed.FS to compile migrations
+// into the binary:
+//
+//	//go:embed sql/*.sql
+//	var migrations embed.FS
+//
+//	err := migrator.MigrateFS(migrations, "sql")
+func FSSource(fsys fs.FS, dir string) MigrationSource {
+	return fsSource{fsys: fsys, dir: dir, name: dir}
+}
+
+type fsSource struct {
+	fsys fs.FS
+	dir  string
+
+	// name identifies the directory in error messages.
+	name string
+}
+
+func (s fsSource) MigrationFiles() ([]MigrationFile, error) {
+	entries, err := fs.ReadDir(s.fsys, s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read migrations: %w", err)
+	}
+
+	var files []MigrationFile
+	for _, entry := range entries {
+		if entry.IsDir() || fileSuffix(entry.Name()) != "sql" {
+			continue
+		}
+
+		b, err := fs.ReadFile(s.fsys, path.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read migration %s: %w", entry.Name(), err)
+		}
+
+		files = append(files, MigrationFile{Name: entry.Name(), SQL: string(b)})
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no migrations found in folder '%s'", s.name)
+	}
+
+	return files, nil
+}
+
+// loadMigrations reads the source's migrations, pairing up and down files
+// together.
+func loadMigrations(src MigrationSource) ([]migration, error) {
+	files, err := src.MigrationFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	contents := map[string]string{}
+	var filenames []string
+	for _, file := range files {
+		if fileSuffix(file.Name) != "sql" {
+			continue
+		}
+
+		if _, ok := contents[file.Name]; ok {
+			return nil, fmt.Errorf("migration %s is provided more than once", file.Name)
+		}
+
+		contents[file.Name] = file.SQL
+		filenames = append(filenames, file.Name)
+	}
+
+	migrations, err := parseMigrations(filenames, contents)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(migrations) == 0 {
+		return nil, errors.New("no migrations found")
+	}
+
+	return migrations, nil
+}
@@ -0,0 +1,68 @@
+package kin
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestFSSource(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/2__create_bar.sql":        {Data: []byte("create table bar (id int);")},
+		"sql/10__create_baz.up.sql":    {Data: []byte("create table baz (id int);")},
+		"sql/10__create_baz.down.sql":  {Data: []byte("drop table baz;")},
+		"sql/README.md":                {Data: []byte("# Migrations")},
+		"sql/nested/1__create_foo.sql": {Data: []byte("create table foo (id int);")},
+	}
+
+	migrations, err := loadMigrations(FSSource(fsys, "sql"))
+	if err != nil {
+		t.Errorf("loadMigrations(...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	want := []string{"2__create_bar.sql", "10__create_baz.up.sql"}
+	if len(migrations) != len(want) {
+		t.Errorf("loadMigrations(...) = %+v, want %v", migrations, want)
+		return
+	}
+
+	for i, mg := range migrations {
+		if mg.filename != want[i] {
+			t.Errorf("loadMigrations(...)[%d] = %s, want %s", i, mg.filename, want[i])
+		}
+	}
+
+	if !migrations[1].reversible || migrations[1].down != "drop table baz;" {
+		t.Errorf("loadMigrations(...)[1] = %+v, want down migration drop table baz;", migrations[1])
+	}
+
+	if _, err := loadMigrations(FSSource(fsys, "sql/nested/missing")); err == nil {
+		t.Error("loadMigrations(missing dir) = (_, <nil>), want (_, error)")
+	}
+}
+
+func TestMemorySource(t *testing.T) {
+	src := MemorySource{
+		{Name: "1__create_foo.sql", SQL: "create table foo (id int);"},
+		{Name: "2__create_bar.sql", SQL: "create table bar (id int);"},
+	}
+
+	migrations, err := loadMigrations(src)
+	if err != nil {
+		t.Errorf("loadMigrations(...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	if len(migrations) != 2 || migrations[0].up != "create table foo (id int);" {
+		t.Errorf("loadMigrations(...) = %+v, want 1__create_foo.sql and 2__create_bar.sql", migrations)
+	}
+
+	duplicated := append(src, MigrationFile{Name: "1__create_foo.sql", SQL: "select 1;"})
+	if _, err := loadMigrations(duplicated); err == nil {
+		t.Error("loadMigrations(duplicated) = (_, <nil>), want (_, error)")
+	}
+
+	if _, err := loadMigrations(MemorySource{}); err == nil {
+		t.Error("loadMigrations(empty) = (_, <nil>), want (_, error)")
+	}
+}
@@ -0,0 +1,181 @@
+package kin
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MigrationState describes whether a migration has been applied.
+type MigrationState int
+
+const (
+	// MigrationPending is a migration that hasn't been applied yet.
+	MigrationPending MigrationState = iota
+
+	// MigrationApplied is a migration that has been applied.
+	MigrationApplied
+
+	// MigrationMissing is a migration that has been applied, but whose file
+	// no longer exists.
+	MigrationMissing
+)
+
+func (s MigrationState) String() string {
+	switch s {
+	case MigrationPending:
+		return "pending"
+	case MigrationApplied:
+		return "applied"
+	case MigrationMissing:
+		return "missing"
+	default:
+		return fmt.Sprintf("MigrationState(%d)", int(s))
+	}
+}
+
+// ChecksumState describes whether an applied migration's file matches the
+// checksum that was recorded when it was applied.
+type ChecksumState int
+
+const (
+	// ChecksumUnknown is the state of migrations that can't be compared:
+	// pending and missing migrations, and migrations that were applied
+	// before checksums were recorded.
+	ChecksumUnknown ChecksumState = iota
+
+	// ChecksumMatch is an applied migration whose file hasn't changed.
+	ChecksumMatch
+
+	// ChecksumMismatch is an applied migration whose file has changed since
+	// it was applied.
+	ChecksumMismatch
+)
+
+func (s ChecksumState) String() string {
+	switch s {
+	case ChecksumUnknown:
+		return "unknown"
+	case ChecksumMatch:
+		return "match"
+	case ChecksumMismatch:
+		return "mismatch"
+	default:
+		return fmt.Sprintf("ChecksumState(%d)", int(s))
+	}
+}
+
+// MigrationStatus is the status of a single migration, as reported by
+// Migrator.Status.
+type MigrationStatus struct {
+	Version     int64
+	Description string
+	Filename    string
+	State       MigrationState
+	Checksum    ChecksumState
+
+	// AppliedOn is when the migration was applied. It's nil for pending
+	// migrations.
+	AppliedOn *time.Time
+}
+
+// Status reports the state of every migration in the folder, along with the
+// applied migrations whose files no longer exist, sorted by version. It
+// doesn't run any migrations or change the database.
+func (m Migrator) Status(folderPath string) ([]MigrationStatus, error) {
+	return m.StatusSource(DirSource(folderPath))
+}
+
+// StatusSource is like Status, but reads the migrations from the source.
+func (m Migrator) StatusSource(src MigrationSource) ([]MigrationStatus, error) {
+	migrations, err := loadMigrations(src)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := readExistingMigrations(m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrationStatuses(migrations, applied), nil
+}
+
+// readExistingMigrations is like readAppliedMigrations, but doesn't require
+// the schemas table to exist, so that it can be read without setting it up.
+func readExistingMigrations(q Querier) ([]appliedMigration, error) {
+	res, err := q.Query("SELECT to_regclass('schemas') IS NOT NULL AS exists").One()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get applied migrations: %w", err)
+	}
+
+	exists := res.ExtractBool("exists")
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("Unable to get applied migrations: %w", err)
+	}
+
+	if !exists {
+		return nil, nil
+	}
+
+	return readAppliedMigrations(q)
+}
+
+func migrationStatuses(migrations []migration, applied []appliedMigration) []MigrationStatus {
+	byFilename := map[string]appliedMigration{}
+	for _, a := range applied {
+		byFilename[a.filename] = a
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	found := map[string]bool{}
+	for _, mg := range migrations {
+		status := MigrationStatus{
+			Version:     mg.version,
+			Description: mg.description,
+			Filename:    mg.filename,
+		}
+
+		if a, ok := byFilename[mg.filename]; ok {
+			found[mg.filename] = true
+			appliedOn := a.appliedOn
+			status.State = MigrationApplied
+			status.AppliedOn = &appliedOn
+
+			switch {
+			case a.checksum == nil:
+				status.Checksum = ChecksumUnknown
+			case *a.checksum == mg.checksum:
+				status.Checksum = ChecksumMatch
+			default:
+				status.Checksum = ChecksumMismatch
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for _, a := range applied {
+		if found[a.filename] {
+			continue
+		}
+
+		// The filename was validated when it was applied, but it may have
+		// been recorded by an older version that didn't validate names.
+		version, description, _ := parseMigrationName(a.filename)
+		appliedOn := a.appliedOn
+		statuses = append(statuses, MigrationStatus{
+			Version:     version,
+			Description: description,
+			Filename:    a.filename,
+			State:       MigrationMissing,
+			AppliedOn:   &appliedOn,
+		})
+	}
+
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses
+}
@@ -0,0 +1,143 @@
+package kin
+
+import (
+	"bytes"
+	"database/sql"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/jmataya/renv/autoload"
+)
+
+func TestMigrationStatuses(t *testing.T) {
+	migrations := []migration{
+		{filename: "1__create_foo.sql", version: 1, description: "create_foo", checksum: "a"},
+		{filename: "3__create_baz.sql", version: 3, description: "create_baz", checksum: "c"},
+		{filename: "4__create_qux.sql", version: 4, description: "create_qux", checksum: "d"},
+		{filename: "5__create_quux.sql", version: 5, description: "create_quux", checksum: "e"},
+	}
+
+	match, mismatch := "a", "x"
+	appliedOn := time.Date(2018, 8, 1, 16, 6, 30, 0, time.UTC)
+	applied := []appliedMigration{
+		{id: 1, filename: "1__create_foo.sql", checksum: &match, appliedOn: appliedOn},
+		{id: 2, filename: "2__create_bar.sql", checksum: &match, appliedOn: appliedOn},
+		{id: 3, filename: "3__create_baz.sql", checksum: &mismatch, appliedOn: appliedOn},
+		{id: 4, filename: "4__create_qux.sql", appliedOn: appliedOn},
+	}
+
+	want := []MigrationStatus{
+		{Version: 1, Description: "create_foo", Filename: "1__create_foo.sql", State: MigrationApplied, Checksum: ChecksumMatch},
+		{Version: 2, Description: "create_bar", Filename: "2__create_bar.sql", State: MigrationMissing, Checksum: ChecksumUnknown},
+		{Version: 3, Description: "create_baz", Filename: "3__create_baz.sql", State: MigrationApplied, Checksum: ChecksumMismatch},
+		{Version: 4, Description: "create_qux", Filename: "4__create_qux.sql", State: MigrationApplied, Checksum: ChecksumUnknown},
+		{Version: 5, Description: "create_quux", Filename: "5__create_quux.sql", State: MigrationPending, Checksum: ChecksumUnknown},
+	}
+
+	statuses := migrationStatuses(migrations, applied)
+	if len(statuses) != len(want) {
+		t.Errorf("migrationStatuses(...) = %+v, want %+v", statuses, want)
+		return
+	}
+
+	for i, status := range statuses {
+		gotAppliedOn := status.AppliedOn
+		status.AppliedOn = nil
+		if status != want[i] {
+			t.Errorf("migrationStatuses(...)[%d] = %+v, want %+v", i, status, want[i])
+		}
+
+		if pending := want[i].State == MigrationPending; pending != (gotAppliedOn == nil) {
+			t.Errorf("migrationStatuses(...)[%d].AppliedOn = %v, want set only when applied", i, gotAppliedOn)
+		}
+	}
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	migrationPath := "./sql"
+
+	cleanupMigrationDir(migrationPath)
+	if err := setupMigrationDir(migrationPath); err != nil {
+		t.Errorf("setupMigrationDir = %v", err)
+		return
+	}
+	defer cleanupMigrationDir(migrationPath)
+
+	createCorge := "create table corge (id serial primary key);"
+	if err := createFile(migrationPath, "1__create_corge.sql", createCorge); err != nil {
+		t.Errorf("createFile = %v", err)
+		return
+	}
+
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	var out bytes.Buffer
+	db, _ := sql.Open("postgres", connStr)
+	migrator, _ := NewMigrator(db, DryRun(&out))
+
+	if err := migrator.Migrate(migrationPath); err != nil {
+		t.Errorf("migrator.Migrate(%s) = %v, want nil", migrationPath, err)
+		return
+	}
+
+	if !strings.Contains(out.String(), createCorge) {
+		t.Errorf("dry run output = %q, want it to contain %q", out.String(), createCorge)
+	}
+
+	statuses, err := migrator.Status(migrationPath)
+	if err != nil {
+		t.Errorf("migrator.Status(%s) = (_, %v), want (_, <nil>)", migrationPath, err)
+		return
+	}
+
+	if len(statuses) != 1 || statuses[0].State != MigrationPending {
+		t.Errorf("migrator.Status(%s) = %+v, want 1__create_corge.sql pending", migrationPath, statuses)
+	}
+}
+
+func TestMigrateDryRunChecksumBackfill(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	src := MemorySource{{Name: "1__create_grault.sql", SQL: "create table grault (id serial primary key);"}}
+
+	db, _ := sql.Open("postgres", connStr)
+	migrator, _ := NewMigrator(db, OnDrift(DriftWarn))
+	if err := migrator.MigrateSource(src); err != nil {
+		t.Errorf("migrator.MigrateSource(...) = %v, want nil", err)
+		return
+	}
+
+	if _, err := db.Exec("UPDATE schemas SET checksum = NULL WHERE filename = $1", src[0].Name); err != nil {
+		t.Errorf("db.Exec(UPDATE schemas ...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	dryRun, _ := NewMigrator(db, OnDrift(DriftWarn), DryRun(io.Discard))
+	if err := dryRun.MigrateSource(src); err != nil {
+		t.Errorf("dryRun.MigrateSource(...) = %v, want nil", err)
+		return
+	}
+
+	statuses, err := migrator.StatusSource(src)
+	if err != nil {
+		t.Errorf("migrator.StatusSource(...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	for _, status := range statuses {
+		if status.Filename == src[0].Name && status.Checksum != ChecksumUnknown {
+			t.Errorf("status of %s = %s, want %s", status.Filename, status.Checksum, ChecksumUnknown)
+		}
+	}
+}
@@ -1,10 +1,17 @@
 package kin
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"io/fs"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -12,37 +19,176 @@ const (
 		CREATE TABLE IF NOT EXISTS schemas (
 			id serial primary key,
 			filename text not null check(length(filename) <= 255),
-			applied_on timestamp without time zone default (now() at time zone 'utc')
+			applied_on timestamp without time zone default (now() at time zone 'utc'),
+			checksum text
 		)
 	`
 
-	sqlInsertSchema = "INSERT INTO schemas (filename) VALUES ($1)"
+	// Schemas tables created before checksums were recorded need the column
+	// added.
+	sqlAddSchemasChecksum = "ALTER TABLE schemas ADD COLUMN IF NOT EXISTS checksum text"
+
+	sqlInsertSchema         = "INSERT INTO schemas (filename, checksum) VALUES ($1, $2)"
+	sqlDeleteSchema         = "DELETE FROM schemas WHERE id = $1"
+	sqlUpdateSchemaChecksum = "UPDATE schemas SET checksum = $1 WHERE id = $2"
+
+	// downMarker separates the up and down sections of a migration that's
+	// written in a single file.
+	downMarker = "-- migrate:down"
 )
 
+// ErrMigrationDrift is returned when a migration has been changed or deleted
+// after it was applied.
+var ErrMigrationDrift = errors.New("applied migrations have changed")
+
+// DriftAction is what a Migrator does when it finds that a migration has
+// been changed or deleted after it was applied.
+type DriftAction int
+
+const (
+	// DriftFail stops the migration with ErrMigrationDrift.
+	DriftFail DriftAction = iota
+
+	// DriftWarn prints a warning and carries on.
+	DriftWarn
+)
+
+// MigratorOption configures optional behavior of a Migrator.
+type MigratorOption func(*migratorOptions)
+
+type migratorOptions struct {
+	drift  DriftAction
+	dryRun io.Writer
+}
+
+// errDryRun rolls back the transaction of a dry run.
+var errDryRun = errors.New("dry run")
+
+// OnDrift sets what the Migrator does when an applied migration has been
+// changed or deleted. By default, it fails with ErrMigrationDrift.
+func OnDrift(action DriftAction) MigratorOption {
+	return func(o *migratorOptions) {
+		o.drift = action
+	}
+}
+
+// DryRun makes the Migrator write the SQL that it would run to w instead of
+// running it. Nothing in the database is changed: the schemas table is only
+// read, if it exists, and isn't created or upgraded.
+func DryRun(w io.Writer) MigratorOption {
+	return func(o *migratorOptions) {
+		o.dryRun = w
+	}
+}
+
 // Migrator is a structure used for running database migrations.
+//
+// Each migration is a SQL file named with a version and a description, such
+// as 1__create_users.sql. The version is a positive integer, which can also be
+// a timestamp such as 20180801160630, and migrations are run in the order of
+// their versions. Migrations that can be reverted come as a pair of
+// files, 1__create_users.up.sql and 1__create_users.down.sql, or as a single
+// file in which the SQL that reverts the migration follows a line containing
+// only "-- migrate:down". Migrations are read from a folder, from a file
+// system such as an embed.FS, or from any other MigrationSource.
+//
+// A checksum of each migration's up and down SQL is recorded when it's
+// applied, and checked every time migrations are run, to catch migrations that
+// were edited or deleted after they were applied. Repair accepts the current
+// files as they are.
 type Migrator struct {
-	db Database
+	db   Database
+	opts migratorOptions
+}
+
+// migration is a migration read from a migration source.
+type migration struct {
+	// filename is the name of the file that applies the migration, which is
+	// recorded in the schemas table once it's been applied.
+	filename string
+
+	version     int64
+	description string
+	checksum    string
+
+	up   string
+	down string
+
+	// reversible is true when the migration has SQL to revert it.
+	reversible bool
+}
+
+// parseMigrationName parses the version and description from a migration's
+// filename, which must be <version>__<description>.sql, optionally with .up
+// or .down before the extension.
+func parseMigrationName(filename string) (int64, string, error) {
+	name := filename
+	for _, suffix := range []string{".up.sql", ".down.sql", ".sql"} {
+		if strings.HasSuffix(name, suffix) {
+			name = strings.TrimSuffix(name, suffix)
+			break
+		}
+	}
+
+	parts := strings.SplitN(name, "__", 2)
+	if len(parts) < 2 || parts[1] == "" || name == filename {
+		return 0, "", fmt.Errorf("migration %s must be named <version>__<description>.sql", filename)
+	}
+
+	for _, c := range parts[0] {
+		if c < '0' || c > '9' {
+			return 0, "", fmt.Errorf("migration %s has an invalid version %s", filename, parts[0])
+		}
+	}
+
+	version, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || version < 1 {
+		return 0, "", fmt.Errorf("migration %s has an invalid version %s", filename, parts[0])
+	}
+
+	return version, parts[1], nil
+}
+
+// appliedMigration is a migration that's recorded in the schemas table.
+type appliedMigration struct {
+	id       int
+	filename string
+
+	// checksum is nil for migrations that were applied before checksums were
+	// recorded.
+	checksum *string
+
+	appliedOn time.Time
 }
 
 // NewMigrator creates a new Migrator around an existing DB connection.
-func NewMigrator(db *sql.DB) (*Migrator, error) {
+func NewMigrator(db *sql.DB, opts ...MigratorOption) (*Migrator, error) {
 	dbi, err := New(db)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Migrator{db: dbi}, nil
+	return newMigrator(dbi, opts), nil
 }
 
 // NewMigratorConnection initialized a DB connection and uses it to power
 // migrations.
-func NewMigratorConnection(dbURL string) (*Migrator, error) {
+func NewMigratorConnection(dbURL string, opts ...MigratorOption) (*Migrator, error) {
 	db, err := NewConnection(dbURL)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Migrator{db: db}, nil
+	return newMigrator(db, opts), nil
+}
+
+func newMigrator(db Database, opts []MigratorOption) *Migrator {
+	m := &Migrator{db: db}
+	for _, opt := range opts {
+		opt(&m.opts)
+	}
+
+	return m
 }
 
 // Close terminates the DB connection. Once this occurs there can be no future
@@ -53,86 +199,510 @@ func (m Migrator) Close() error {
 
 // Migrate runs a series of database migrations.
 func (m Migrator) Migrate(folderPath string) error {
+	return m.MigrateSource(DirSource(folderPath))
+}
+
+// MigrateFS runs the migrations in the directory of the file system, such as
+// an embed.FS that's compiled into the binary.
+func (m Migrator) MigrateFS(fsys fs.FS, dir string) error {
+	return m.MigrateSource(FSSource(fsys, dir))
+}
+
+// MigrateSource runs the migrations provided by the source.
+func (m Migrator) MigrateSource(src MigrationSource) error {
 	fmt.Println("Starting database migrations...")
 	fmt.Println("")
 
-	files, err := ioutil.ReadDir(folderPath)
+	migrations, err := loadMigrations(src)
 	if err != nil {
-		return fmt.Errorf("unable to read migrations: %v", err)
+		return err
 	}
 
-	sqlFiles := []string{}
-	for _, file := range files {
-		if !file.IsDir() && fileSuffix(file.Name()) == "sql" {
-			sqlFiles = append(sqlFiles, file.Name())
+	return m.transaction(func(txn Transaction) error {
+		applied, err := m.appliedMigrations(txn)
+		if err != nil {
+			return err
+		}
+
+		if err := verifyChecksums(txn, migrations, applied, m.opts.drift, m.opts.dryRun); err != nil {
+			return err
 		}
+
+		appliedMigrations := map[string]bool{}
+		for _, a := range applied {
+			appliedMigrations[a.filename] = true
+		}
+
+		for _, mg := range migrations {
+			if appliedMigrations[mg.filename] {
+				fmt.Printf("-------- Running %s...SKIPPED\n", mg.filename)
+				continue
+			}
+
+			if err := applyMigration(txn, mg, m.opts.dryRun); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Rollback reverts the most recently applied migrations, up to the number of
+// steps, in the reverse order that they were applied. Every migration that's
+// reverted must have down SQL.
+func (m Migrator) Rollback(folderPath string, steps int) error {
+	return m.RollbackSource(DirSource(folderPath), steps)
+}
+
+// RollbackSource is like Rollback, but reads the migrations from the source.
+func (m Migrator) RollbackSource(src MigrationSource, steps int) error {
+	if steps < 0 {
+		return fmt.Errorf("rollback steps must not be negative, got %d", steps)
 	}
 
-	if len(sqlFiles) == 0 {
-		return fmt.Errorf("no migrations found in folder '%s'", folderPath)
+	fmt.Println("Starting database rollback...")
+	fmt.Println("")
+
+	migrations, err := loadMigrations(src)
+	if err != nil {
+		return err
+	}
+
+	return m.transaction(func(txn Transaction) error {
+		applied, err := m.appliedMigrations(txn)
+		if err != nil {
+			return err
+		}
+
+		if err := verifyChecksums(txn, migrations, applied, m.opts.drift, m.opts.dryRun); err != nil {
+			return err
+		}
+
+		if steps > len(applied) {
+			steps = len(applied)
+		}
+
+		return revertMigrations(txn, migrations, applied[len(applied)-steps:], m.opts.dryRun)
+	})
+}
+
+// MigrateTo moves the database to the migration with the version. Pending
+// migrations up to and including the version are applied, and applied
+// migrations after it are reverted, in the reverse order that they were
+// applied. Applied migrations whose recorded filenames don't have a
+// version, which older versions of the migrator didn't require, are skipped
+// with a warning; rename them in the schemas table to have them reverted.
+func (m Migrator) MigrateTo(folderPath string, version int64) error {
+	return m.MigrateToSource(DirSource(folderPath), version)
+}
+
+// MigrateToSource is like MigrateTo, but reads the migrations from the
+// source.
+func (m Migrator) MigrateToSource(src MigrationSource, version int64) error {
+	fmt.Printf("Starting database migrations to version %d...\n", version)
+	fmt.Println("")
+
+	migrations, err := loadMigrations(src)
+	if err != nil {
+		return err
 	}
 
-	txn, err := m.db.StartTransaction()
+	return m.transaction(func(txn Transaction) error {
+		applied, err := m.appliedMigrations(txn)
+		if err != nil {
+			return err
+		}
+
+		if err := verifyChecksums(txn, migrations, applied, m.opts.drift, m.opts.dryRun); err != nil {
+			return err
+		}
+
+		appliedMigrations := map[string]bool{}
+		for _, a := range applied {
+			appliedMigrations[a.filename] = true
+		}
+
+		if err := revertMigrations(txn, migrations, appliedAfter(applied, version), m.opts.dryRun); err != nil {
+			return err
+		}
+
+		for _, mg := range migrations {
+			if appliedMigrations[mg.filename] || mg.version > version {
+				continue
+			}
+
+			if err := applyMigration(txn, mg, m.opts.dryRun); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Repair accepts the migration files as they are: the checksums of applied
+// migrations are updated to match their files, and applied migrations whose
+// files were deleted are removed from the schemas table. No migrations are
+// run.
+func (m Migrator) Repair(folderPath string) error {
+	return m.RepairSource(DirSource(folderPath))
+}
+
+// RepairSource is like Repair, but reads the migrations from the source.
+func (m Migrator) RepairSource(src MigrationSource) error {
+	fmt.Println("Starting migrations repair...")
+	fmt.Println("")
+
+	migrations, err := loadMigrations(src)
 	if err != nil {
-		return fmt.Errorf("Unexpected error starting transaction: %v", err)
+		return err
+	}
+
+	byFilename := map[string]migration{}
+	for _, mg := range migrations {
+		byFilename[mg.filename] = mg
 	}
 
+	return m.transaction(func(txn Transaction) error {
+		applied, err := m.appliedMigrations(txn)
+		if err != nil {
+			return err
+		}
+
+		for _, a := range applied {
+			mg, ok := byFilename[a.filename]
+			switch {
+			case !ok:
+				fmt.Printf("-------- Removing %s...", a.filename)
+				if m.opts.dryRun == nil {
+					_, err = txn.Exec(sqlDeleteSchema, a.id)
+				}
+			case a.checksum == nil || *a.checksum != mg.checksum:
+				fmt.Printf("-------- Updating checksum of %s...", a.filename)
+				if m.opts.dryRun == nil {
+					_, err = txn.Exec(sqlUpdateSchemaChecksum, mg.checksum, a.id)
+				}
+			default:
+				continue
+			}
+
+			if m.opts.dryRun != nil {
+				fmt.Printf("DRY RUN\n")
+				continue
+			}
+
+			if err != nil {
+				fmt.Printf("FAILED\n")
+				fmt.Println("-------- Rolling back changes.")
+				return fmt.Errorf("Error updating schemas table with %s: %w", a.filename, err)
+			}
+
+			fmt.Printf("COMPLETED\n")
+		}
+
+		return nil
+	})
+}
+
+// transaction runs fn inside of a transaction, which is always rolled back
+// during a dry run.
+func (m Migrator) transaction(fn func(Transaction) error) error {
+	if m.opts.dryRun == nil {
+		return m.db.WithTransaction(fn)
+	}
+
+	err := m.db.WithTransaction(func(txn Transaction) error {
+		if err := fn(txn); err != nil {
+			return err
+		}
+
+		return errDryRun
+	})
+
+	if err == errDryRun {
+		return nil
+	}
+
+	return err
+}
+
+// appliedMigrations returns the applied migrations in the order that they were
+// applied. A dry run doesn't set up the schemas table, and reads it like Status
+// does, only if it exists.
+func (m Migrator) appliedMigrations(txn Transaction) ([]appliedMigration, error) {
+	if m.opts.dryRun == nil {
+		return setupSchemas(txn)
+	}
+
+	return readExistingMigrations(txn)
+}
+
+// setupSchemas creates the schemas table if it doesn't exist yet and returns
+// the applied migrations in the order that they were applied.
+func setupSchemas(txn Transaction) ([]appliedMigration, error) {
 	fmt.Printf("-------- Ensuring database is set up...")
-	if err := txn.Exec(sqlCreateSchemasTable); err != nil {
+	if _, err := txn.Exec(sqlCreateSchemasTable); err != nil {
+		fmt.Printf("FAILED\n")
+		return nil, fmt.Errorf("Error setting up schemas table: %w", err)
+	}
+
+	if _, err := txn.Exec(sqlAddSchemasChecksum); err != nil {
 		fmt.Printf("FAILED\n")
-		return fmt.Errorf("Error setting up schemas table: %v", err)
+		return nil, fmt.Errorf("Error setting up schemas table: %w", err)
 	}
 
 	fmt.Printf("COMPLETED\n")
 
-	res, err := txn.Query("SELECT * FROM schemas").Run()
+	return readAppliedMigrations(txn)
+}
+
+// readAppliedMigrations returns the migrations recorded in the schemas table
+// in the order that they were applied.
+func readAppliedMigrations(q Querier) ([]appliedMigration, error) {
+	res, err := q.Query("SELECT * FROM schemas ORDER BY id").Run()
 	if err != nil {
-		txn.Rollback()
-		return fmt.Errorf("Unable to get applied migrations: %v", err)
+		return nil, fmt.Errorf("Unable to get applied migrations: %w", err)
 	}
 
-	appliedMigrations := map[string]int{}
-	for _, rowRes := range res.Rows {
-		id := rowRes.ExtractInt("id")
-		filename := rowRes.ExtractString("filename")
-		appliedMigrations[filename] = id
+	// The checksum column is only added when migrations are run, so a table
+	// that's older than checksums may not have it yet.
+	hasChecksum := contains(res.Columns, "checksum")
+
+	applied := make([]appliedMigration, len(res.Rows))
+	for i, rowRes := range res.Rows {
+		applied[i] = appliedMigration{
+			id:        rowRes.ExtractInt("id"),
+			filename:  rowRes.ExtractString("filename"),
+			appliedOn: rowRes.ExtractTime("applied_on"),
+		}
+
+		if hasChecksum {
+			applied[i].checksum = rowRes.ExtractNullString("checksum")
+		}
+
+		if err := rowRes.Err(); err != nil {
+			return nil, fmt.Errorf("Unable to get applied migrations: %w", err)
+		}
 	}
 
-	for _, sqlFile := range sqlFiles {
-		fmt.Printf("-------- Running %s...", sqlFile)
-		if _, ok := appliedMigrations[sqlFile]; ok {
-			fmt.Printf("SKIPPED\n")
-			continue
+	return applied, nil
+}
+
+// verifyChecksums checks that the applied migrations haven't been changed or
+// deleted since they were applied. Migrations that were applied before
+// checksums were recorded get the checksum of their current file, except in a
+// dry run.
+func verifyChecksums(txn Transaction, migrations []migration, applied []appliedMigration, action DriftAction, dryRun io.Writer) error {
+	byFilename := map[string]migration{}
+	for _, mg := range migrations {
+		byFilename[mg.filename] = mg
+	}
+
+	var drifted []string
+	for _, a := range applied {
+		mg, ok := byFilename[a.filename]
+		switch {
+		case !ok:
+			drifted = append(drifted, a.filename+" was deleted")
+		case a.checksum == nil:
+			if dryRun != nil {
+				continue
+			}
+
+			if _, err := txn.Exec(sqlUpdateSchemaChecksum, mg.checksum, a.id); err != nil {
+				return fmt.Errorf("Error updating schemas table with %s: %w", a.filename, err)
+			}
+		case *a.checksum != mg.checksum:
+			drifted = append(drifted, a.filename+" was changed")
 		}
+	}
 
-		file, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", folderPath, sqlFile))
-		if err != nil {
-			fmt.Printf("FAILED\n")
-			fmt.Println("-------- Rolling back changes.")
-			txn.Rollback()
-			return fmt.Errorf("Error executing %s: %v", sqlFile, err)
+	if len(drifted) == 0 {
+		return nil
+	}
+
+	if action == DriftWarn {
+		for _, d := range drifted {
+			fmt.Printf("-------- WARNING: applied migration %s\n", d)
 		}
 
-		err = txn.Exec(string(file))
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrMigrationDrift, strings.Join(drifted, ", "))
+}
+
+// appliedAfter returns the applied migrations with versions after the version.
+// The versions come from the recorded filenames, since the files may no longer
+// exist.
+func appliedAfter(applied []appliedMigration, version int64) []appliedMigration {
+	var after []appliedMigration
+	for _, a := range applied {
+		v, _, err := parseMigrationName(a.filename)
 		if err != nil {
+			fmt.Printf("-------- WARNING: skipping applied migration %s: %v\n", a.filename, err)
+			continue
+		}
+
+		if v > version {
+			after = append(after, a)
+		}
+	}
+
+	return after
+}
+
+// applyMigration runs the migration and records it in the schemas table. In a
+// dry run, its SQL is written to dryRun instead.
+func applyMigration(txn Transaction, mg migration, dryRun io.Writer) error {
+	fmt.Printf("-------- Running %s...", mg.filename)
+
+	if dryRun != nil {
+		fmt.Fprintf(dryRun, "-- %s\n%s\n", mg.filename, strings.TrimSpace(mg.up))
+		fmt.Printf("DRY RUN\n")
+		return nil
+	}
+
+	if _, err := txn.Exec(mg.up); err != nil {
+		fmt.Printf("FAILED\n")
+		fmt.Println("-------- Rolling back changes.")
+		return fmt.Errorf("Error executing %s: %w", mg.filename, err)
+	}
+
+	if _, err := txn.Exec(sqlInsertSchema, mg.filename, mg.checksum); err != nil {
+		fmt.Printf("FAILED\n")
+		fmt.Println("-------- Rolling back changes.")
+		return fmt.Errorf("Error updating schemas table with %s: %w", mg.filename, err)
+	}
+
+	fmt.Printf("COMPLETED\n")
+	return nil
+}
+
+// revertMigrations reverts the applied migrations, starting from the last
+// one. It checks that every migration can be reverted before reverting any.
+// In a dry run, the SQL that reverts them is written to dryRun instead.
+func revertMigrations(txn Transaction, migrations []migration, applied []appliedMigration, dryRun io.Writer) error {
+	byFilename := map[string]migration{}
+	for _, mg := range migrations {
+		byFilename[mg.filename] = mg
+	}
+
+	for _, a := range applied {
+		mg, ok := byFilename[a.filename]
+		if !ok {
+			return fmt.Errorf("applied migration %s not found", a.filename)
+		}
+
+		if !mg.reversible {
+			return fmt.Errorf("migration %s has no down migration", a.filename)
+		}
+	}
+
+	for i := len(applied) - 1; i >= 0; i-- {
+		a := applied[i]
+		mg := byFilename[a.filename]
+
+		fmt.Printf("-------- Reverting %s...", mg.filename)
+
+		if dryRun != nil {
+			fmt.Fprintf(dryRun, "-- %s (down)\n%s\n", mg.filename, strings.TrimSpace(mg.down))
+			fmt.Printf("DRY RUN\n")
+			continue
+		}
+
+		if _, err := txn.Exec(mg.down); err != nil {
 			fmt.Printf("FAILED\n")
 			fmt.Println("-------- Rolling back changes.")
-			txn.Rollback()
-			return fmt.Errorf("Error executing %s: %v", sqlFile, err)
+			return fmt.Errorf("Error reverting %s: %w", mg.filename, err)
 		}
 
-		err = txn.Exec(sqlInsertSchema, sqlFile)
-		if err != nil {
+		if _, err := txn.Exec(sqlDeleteSchema, a.id); err != nil {
 			fmt.Printf("FAILED\n")
 			fmt.Println("-------- Rolling back changes.")
-			txn.Rollback()
-			return fmt.Errorf("Error updating schemas table with %s: %v", sqlFile, err)
+			return fmt.Errorf("Error updating schemas table with %s: %w", mg.filename, err)
 		}
 
 		fmt.Printf("COMPLETED\n")
 	}
 
-	return txn.Commit()
+	return nil
+}
+
+// parseMigrations builds the migrations from the files' contents and sorts
+// them by version. Every filename is validated, and versions must be unique,
+// so that problems are reported before any migration is run.
+func parseMigrations(filenames []string, contents map[string]string) ([]migration, error) {
+	var migrations []migration
+	for _, filename := range filenames {
+		if _, _, err := parseMigrationName(filename); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case strings.HasSuffix(filename, ".down.sql"):
+			up := strings.TrimSuffix(filename, ".down.sql") + ".up.sql"
+			if _, ok := contents[up]; !ok {
+				return nil, fmt.Errorf("down migration %s has no matching up migration %s", filename, up)
+			}
+		case strings.HasSuffix(filename, ".up.sql"):
+			down, reversible := contents[strings.TrimSuffix(filename, ".up.sql")+".down.sql"]
+			migrations = append(migrations, migration{
+				filename:   filename,
+				up:         contents[filename],
+				down:       down,
+				reversible: reversible,
+			})
+		default:
+			up, down, reversible := splitMigration(contents[filename])
+			migrations = append(migrations, migration{
+				filename:   filename,
+				up:         up,
+				down:       down,
+				reversible: reversible,
+			})
+		}
+	}
+
+	versions := map[int64]string{}
+	for i := range migrations {
+		mg := &migrations[i]
+		mg.version, mg.description, _ = parseMigrationName(mg.filename)
+		mg.checksum = checksum(mg.up, mg.down)
+
+		if other, ok := versions[mg.version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s have the same version %d", other, mg.filename, mg.version)
+		}
+		versions[mg.version] = mg.filename
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
+// checksum returns the hex encoded SHA-256 hash of the migration's up SQL, a
+// NUL byte and its down SQL, so that an edited down migration is caught before
+// it's used to roll back.
+func checksum(up, down string) string {
+	sum := sha256.Sum256([]byte(up + "\x00" + down))
+	return hex.EncodeToString(sum[:])
+}
+
+// splitMigration splits a migration file into the SQL that applies it and
+// the SQL that follows the down marker, if there is one.
+func splitMigration(contents string) (string, string, bool) {
+	lines := strings.SplitAfter(contents, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == downMarker {
+			return strings.Join(lines[:i], ""), strings.Join(lines[i+1:], ""), true
+		}
+	}
+
+	return contents, "", false
 }
 
 func fileSuffix(fileName string) string {
@@ -2,7 +2,9 @@ package kin
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"testing"
 
@@ -124,3 +126,212 @@ func TestMigrateRerun(t *testing.T) {
 
 	cleanupMigrationDir(migrationPath)
 }
+
+func TestParseMigrations(t *testing.T) {
+	contents := map[string]string{
+		"1__create_foo.up.sql":   "create table foo (id int);",
+		"1__create_foo.down.sql": "drop table foo;",
+		"2__create_bar.sql":      "create table bar (id int);\n-- migrate:down\ndrop table bar;\n",
+		"3__seed_bar.sql":        "insert into bar values (1);",
+	}
+	filenames := []string{"1__create_foo.down.sql", "1__create_foo.up.sql", "2__create_bar.sql", "3__seed_bar.sql"}
+
+	migrations, err := parseMigrations(filenames, contents)
+	if err != nil {
+		t.Errorf("parseMigrations(...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	want := []migration{
+		{filename: "1__create_foo.up.sql", version: 1, description: "create_foo", up: "create table foo (id int);", down: "drop table foo;", reversible: true},
+		{filename: "2__create_bar.sql", version: 2, description: "create_bar", up: "create table bar (id int);\n", down: "drop table bar;\n", reversible: true},
+		{filename: "3__seed_bar.sql", version: 3, description: "seed_bar", up: "insert into bar values (1);"},
+	}
+
+	if len(migrations) != len(want) {
+		t.Errorf("parseMigrations(...) = %+v, want %+v", migrations, want)
+		return
+	}
+
+	for i, mg := range migrations {
+		want[i].checksum = checksum(want[i].up, want[i].down)
+		if mg != want[i] {
+			t.Errorf("parseMigrations(...)[%d] = %+v, want %+v", i, mg, want[i])
+		}
+	}
+
+	delete(contents, "1__create_foo.up.sql")
+	if _, err := parseMigrations(filenames[:1], contents); err == nil {
+		t.Error("parseMigrations(orphaned down) = (_, <nil>), want (_, error)")
+	}
+}
+
+func TestRollbackAndMigrateTo(t *testing.T) {
+	migrationPath := "./sql"
+
+	cleanupMigrationDir(migrationPath)
+	if err := setupMigrationDir(migrationPath); err != nil {
+		t.Errorf("setupMigrationDir = %v", err)
+		return
+	}
+	defer cleanupMigrationDir(migrationPath)
+
+	files := map[string]string{
+		"1__create_qux.up.sql":   "create table qux (id serial primary key);",
+		"1__create_qux.down.sql": "drop table qux;",
+		"2__create_quux.sql":     "create table quux (id serial primary key);\n-- migrate:down\ndrop table quux;",
+	}
+
+	for name, contents := range files {
+		if err := createFile(migrationPath, name, contents); err != nil {
+			t.Errorf("createFile = %v", err)
+			return
+		}
+	}
+
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, _ := sql.Open("postgres", connStr)
+	migrator, _ := NewMigrator(db)
+
+	if err := migrator.MigrateTo(migrationPath, 1); err != nil {
+		t.Errorf("migrator.MigrateTo(%s, 1) = %v, want nil", migrationPath, err)
+		return
+	}
+
+	if err := migrator.Migrate(migrationPath); err != nil {
+		t.Errorf("migrator.Migrate(%s) = %v, want nil", migrationPath, err)
+		return
+	}
+
+	if err := migrator.Rollback(migrationPath, 1); err != nil {
+		t.Errorf("migrator.Rollback(%s, 1) = %v, want nil", migrationPath, err)
+	}
+
+	if err := migrator.MigrateTo(migrationPath, 0); err != nil {
+		t.Errorf("migrator.MigrateTo(%s, 0) = %v, want nil", migrationPath, err)
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT count(*) FROM schemas WHERE filename IN ('1__create_qux.up.sql', '2__create_quux.sql')").Scan(&count); err != nil || count != 0 {
+		t.Errorf("applied migrations after MigrateTo(0) = (%d, %v), want (0, <nil>)", count, err)
+	}
+}
+
+func TestParseMigrationsOrder(t *testing.T) {
+	filenames := []string{"10__add_index.sql", "20180801160630__add_column.sql", "2__create_bar.sql"}
+	contents := map[string]string{}
+	for _, filename := range filenames {
+		contents[filename] = "select 1;"
+	}
+
+	migrations, err := parseMigrations(filenames, contents)
+	if err != nil {
+		t.Errorf("parseMigrations(...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	want := []string{"2__create_bar.sql", "10__add_index.sql", "20180801160630__add_column.sql"}
+	for i, mg := range migrations {
+		if mg.filename != want[i] {
+			t.Errorf("parseMigrations(...)[%d] = %s, want %s", i, mg.filename, want[i])
+		}
+	}
+}
+
+func TestParseMigrationsInvalid(t *testing.T) {
+	tests := [][]string{
+		{"create_foo.sql"},
+		{"1_create_foo.sql"},
+		{"1__.sql"},
+		{"v1__create_foo.sql"},
+		{"0__create_foo.sql"},
+		{"1__create_foo.sql", "01__create_bar.sql"},
+		{"1__create_foo.up.sql", "1__create_bar.sql"},
+	}
+
+	for _, filenames := range tests {
+		contents := map[string]string{}
+		for _, filename := range filenames {
+			contents[filename] = "select 1;"
+		}
+
+		if _, err := parseMigrations(filenames, contents); err == nil {
+			t.Errorf("parseMigrations(%v) = (_, <nil>), want (_, error)", filenames)
+		}
+	}
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	migrations := []migration{
+		{filename: "1__create_foo.sql", checksum: checksum("create table foo (id int);", "")},
+		{filename: "2__create_bar.sql", checksum: checksum("create table bar (id int);", "")},
+	}
+
+	unchanged := migrations[0].checksum
+	changed := checksum("create table bar (id bigint);", "")
+
+	tests := []struct {
+		applied []appliedMigration
+		action  DriftAction
+		wantErr bool
+	}{
+		{[]appliedMigration{{id: 1, filename: "1__create_foo.sql", checksum: &unchanged}}, DriftFail, false},
+		{[]appliedMigration{{id: 1, filename: "1__create_foo.sql", checksum: &unchanged}, {id: 2, filename: "2__create_bar.sql", checksum: &changed}}, DriftFail, true},
+		{[]appliedMigration{{id: 1, filename: "1__create_foo.sql", checksum: &unchanged}, {id: 3, filename: "3__deleted.sql", checksum: &unchanged}}, DriftFail, true},
+		{[]appliedMigration{{id: 2, filename: "2__create_bar.sql", checksum: &changed}, {id: 3, filename: "3__deleted.sql", checksum: &unchanged}}, DriftWarn, false},
+	}
+
+	for _, test := range tests {
+		err := verifyChecksums(nil, migrations, test.applied, test.action, nil)
+		if test.wantErr && !errors.Is(err, ErrMigrationDrift) {
+			t.Errorf("verifyChecksums(%v) = %v, want %v", test.applied, err, ErrMigrationDrift)
+		}
+
+		if !test.wantErr && err != nil {
+			t.Errorf("verifyChecksums(%v) = %v, want <nil>", test.applied, err)
+		}
+	}
+}
+
+func TestAppliedAfter(t *testing.T) {
+	applied := []appliedMigration{
+		{id: 1, filename: "create_legacy.sql"},
+		{id: 2, filename: "1__create_foo.sql"},
+		{id: 3, filename: "2__create_bar.sql"},
+		{id: 4, filename: "3__create_baz.sql"},
+	}
+
+	got := appliedAfter(applied, 1)
+	if len(got) != 2 || got[0].id != 3 || got[1].id != 4 {
+		t.Errorf("appliedAfter(..., 1) = %v, want migrations 3 and 4", got)
+	}
+}
+
+func TestChecksumDown(t *testing.T) {
+	up := "create table foo (id int);"
+	if checksum(up, "drop table foo;") == checksum(up, "drop table if exists foo;") {
+		t.Errorf("checksum(...) is the same for different down migrations, want different")
+	}
+
+	if checksum(up, "drop table foo;") == checksum(up, "") {
+		t.Errorf("checksum(...) is the same with and without a down migration, want different")
+	}
+}
+
+func TestVerifyChecksumsDryRun(t *testing.T) {
+	migrations := []migration{
+		{filename: "1__create_foo.sql", checksum: checksum("create table foo (id int);", "")},
+	}
+	applied := []appliedMigration{{id: 1, filename: "1__create_foo.sql"}}
+
+	// The transaction is nil, so back-filling the missing checksum would
+	// panic.
+	if err := verifyChecksums(nil, migrations, applied, DriftFail, io.Discard); err != nil {
+		t.Errorf("verifyChecksums(%v) = %v, want <nil>", applied, err)
+	}
+}
@@ -1,5 +1,10 @@
 package kin
 
+import (
+	"errors"
+	"fmt"
+)
+
 // Model is a data structure that can be used to construct a database
 // insert or update.
 type Model interface {
@@ -9,3 +14,99 @@ type Model interface {
 	// TableName returns the name of the table that this model maps to.
 	TableName() string
 }
+
+// PrimaryKeyer is a model whose rows are uniquely identified by a primary key.
+// It's needed to generate update, delete and lookup queries for the model.
+type PrimaryKeyer interface {
+	Model
+
+	// PrimaryKey returns the field that holds the model's primary key. It
+	// should be one of the fields returned by Columns.
+	PrimaryKey() FieldBuilder
+}
+
+func insertStmt(m Model) (string, []interface{}) {
+	stmt, _, params := insertClause(m)
+	return stmt + " RETURNING *", params
+}
+
+// insertClause generates an insert for every set column in the model, without
+// any trailing clauses. It also returns the names of the inserted columns.
+func insertClause(m Model) (string, []string, []interface{}) {
+	var columns string
+	var values string
+	var names []string
+	var params []interface{}
+
+	for _, column := range m.Columns() {
+		if !column.IsSet() {
+			continue
+		}
+
+		separator := ""
+		if len(params) > 0 {
+			separator = ", "
+		}
+
+		params = append(params, paramValue(column))
+		names = append(names, column.FieldName())
+		columns = fmt.Sprintf("%s%s%s", columns, separator, column.FieldName())
+		values = fmt.Sprintf("%s%s$%d", values, separator, len(params))
+	}
+
+	stmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", m.TableName(), columns, values)
+	return stmt, names, params
+}
+
+// updateStmt generates an update for every set column in the model other
+// than the primary key, using the same rules as inserts to decide which
+// columns are set.
+func updateStmt(m PrimaryKeyer) (string, []interface{}, error) {
+	pk := m.PrimaryKey()
+
+	var assignments string
+	var params []interface{}
+
+	for _, column := range m.Columns() {
+		if column.FieldName() == pk.FieldName() || !column.IsSet() {
+			continue
+		}
+
+		separator := ""
+		if len(params) > 0 {
+			separator = ", "
+		}
+
+		params = append(params, paramValue(column))
+		assignments = fmt.Sprintf("%s%s%s = $%d", assignments, separator, column.FieldName(), len(params))
+	}
+
+	if len(params) == 0 {
+		return "", nil, fmt.Errorf("no columns set to update in %s", m.TableName())
+	}
+
+	if !pk.IsSet() {
+		return "", nil, errPrimaryKeyNotSet
+	}
+
+	params = append(params, paramValue(pk))
+	stmt := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d RETURNING *", m.TableName(), assignments, pk.FieldName(), len(params))
+	return stmt, params, nil
+}
+
+func deleteStmt(m PrimaryKeyer) (string, []interface{}, error) {
+	pk := m.PrimaryKey()
+	if !pk.IsSet() {
+		return "", nil, errPrimaryKeyNotSet
+	}
+
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE %s = $1 RETURNING *", m.TableName(), pk.FieldName())
+	return stmt, []interface{}{paramValue(pk)}, nil
+}
+
+func findStmt(m PrimaryKeyer, key interface{}) (string, []interface{}) {
+	stmt := fmt.Sprintf("SELECT * FROM %s WHERE %s = $1", m.TableName(), m.PrimaryKey().FieldName())
+	return stmt, []interface{}{key}
+}
+
+var errPrimaryKeyNotSet = errors.New("primary key must be set")
@@ -0,0 +1,75 @@
+package kin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// bindNamed rewrites the named placeholders (:name or @name) in the statement
+// to positional ones and returns the parameters in the matching order. A name
+// that's used more than once is bound to a single parameter. When
+// requireUsed is true, every parameter must be referenced by the statement.
+func bindNamed(stmt string, params map[string]interface{}, requireUsed bool) (string, []interface{}, error) {
+	positions := map[string]int{}
+	var positional []interface{}
+
+	rewritten, err := rewritePlaceholders(stmt, func(p placeholder) (string, error) {
+		if p.name == "" {
+			return "", fmt.Errorf("positional parameter %s can't be mixed with named parameters", p)
+		}
+
+		if position, ok := positions[p.name]; ok {
+			return fmt.Sprintf("$%d", position), nil
+		}
+
+		value, ok := params[p.name]
+		if !ok {
+			return "", fmt.Errorf("named parameter %s has no value", p)
+		}
+
+		positional = append(positional, value)
+		positions[p.name] = len(positional)
+		return fmt.Sprintf("$%d", len(positional)), nil
+	})
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if requireUsed {
+		var unused []string
+		for name := range params {
+			if _, ok := positions[name]; !ok {
+				unused = append(unused, name)
+			}
+		}
+
+		if len(unused) > 0 {
+			sort.Strings(unused)
+			return "", nil, fmt.Errorf("named parameters not used in query: %s", strings.Join(unused, ", "))
+		}
+	}
+
+	return rewritten, positional, nil
+}
+
+// modelParams collects the values of the model's columns by field name.
+func modelParams(m Model) map[string]interface{} {
+	params := map[string]interface{}{}
+	for _, column := range m.Columns() {
+		params[column.FieldName()] = paramValue(column)
+	}
+
+	return params
+}
+
+func namedQuery(query func(string, ...interface{}) *Query, stmt string, params map[string]interface{}) *Query {
+	rewritten, positional, err := bindNamed(stmt, params, true)
+	return query(rewritten, positional...).withErr(err)
+}
+
+func namedModelQuery(query func(string, ...interface{}) *Query, stmt string, m Model) *Query {
+	rewritten, positional, err := bindNamed(stmt, modelParams(m), false)
+	return query(rewritten, positional...).withErr(err)
+}
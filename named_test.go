@@ -0,0 +1,119 @@
+package kin
+
+import (
+	"testing"
+)
+
+func TestBindNamed(t *testing.T) {
+	tests := []struct {
+		stmt       string
+		params     map[string]interface{}
+		wantStmt   string
+		wantParams []interface{}
+	}{
+		{
+			stmt:       "SELECT * FROM users WHERE id = :id AND name = @name",
+			params:     map[string]interface{}{"id": 1, "name": "Donkey Hote"},
+			wantStmt:   "SELECT * FROM users WHERE id = $1 AND name = $2",
+			wantParams: []interface{}{1, "Donkey Hote"},
+		},
+		{
+			stmt:       "SELECT :id::int, created_at::date FROM users WHERE id = :id",
+			params:     map[string]interface{}{"id": 1},
+			wantStmt:   "SELECT $1::int, created_at::date FROM users WHERE id = $1",
+			wantParams: []interface{}{1},
+		},
+		{
+			stmt:       "SELECT ':skip', \"@skip\", E'it\\'s :skip', $$ :skip $$, $tag$ @skip $tag$ FROM users WHERE id = :id",
+			params:     map[string]interface{}{"id": 1},
+			wantStmt:   "SELECT ':skip', \"@skip\", E'it\\'s :skip', $$ :skip $$, $tag$ @skip $tag$ FROM users WHERE id = $1",
+			wantParams: []interface{}{1},
+		},
+		{
+			stmt:       "SELECT 'it''s :skip' -- :skip\n/* :skip /* @skip */ */ FROM users WHERE id = :id",
+			params:     map[string]interface{}{"id": 1},
+			wantStmt:   "SELECT 'it''s :skip' -- :skip\n/* :skip /* @skip */ */ FROM users WHERE id = $1",
+			wantParams: []interface{}{1},
+		},
+		{
+			stmt:       "SELECT * FROM users WHERE attributes @> :attrs",
+			params:     map[string]interface{}{"attrs": `{"lang": "en"}`},
+			wantStmt:   "SELECT * FROM users WHERE attributes @> $1",
+			wantParams: []interface{}{`{"lang": "en"}`},
+		},
+	}
+
+	for _, test := range tests {
+		stmt, params, err := bindNamed(test.stmt, test.params, true)
+		if err != nil {
+			t.Errorf("bindNamed(%q) = (_, _, %v), want (_, _, <nil>)", test.stmt, err)
+			continue
+		}
+
+		if stmt != test.wantStmt {
+			t.Errorf("bindNamed(%q) = %q, want %q", test.stmt, stmt, test.wantStmt)
+		}
+
+		if len(params) != len(test.wantParams) {
+			t.Errorf("bindNamed(%q) params = %v, want %v", test.stmt, params, test.wantParams)
+			continue
+		}
+
+		for i, param := range params {
+			if param != test.wantParams[i] {
+				t.Errorf("bindNamed(%q) params[%d] = %v, want %v", test.stmt, i, param, test.wantParams[i])
+			}
+		}
+	}
+}
+
+func TestBindNamedErrors(t *testing.T) {
+	tests := []struct {
+		stmt    string
+		params  map[string]interface{}
+		wantErr string
+	}{
+		{
+			stmt:    "SELECT * FROM users WHERE id = :id",
+			params:  map[string]interface{}{},
+			wantErr: "named parameter :id has no value",
+		},
+		{
+			stmt:    "SELECT * FROM users WHERE id = :id",
+			params:  map[string]interface{}{"id": 1, "name": "x", "age": 2},
+			wantErr: "named parameters not used in query: age, name",
+		},
+		{
+			stmt:    "SELECT * FROM users WHERE id = :id AND name = $2",
+			params:  map[string]interface{}{"id": 1},
+			wantErr: "positional parameter $2 can't be mixed with named parameters",
+		},
+	}
+
+	for _, test := range tests {
+		_, _, err := bindNamed(test.stmt, test.params, true)
+		if err == nil || err.Error() != test.wantErr {
+			t.Errorf("bindNamed(%q) = (_, _, %v), want (_, _, %s)", test.stmt, err, test.wantErr)
+		}
+	}
+}
+
+func TestNamedModelQuery(t *testing.T) {
+	tm := testModel{ID: 1, Name: "Donkey Hote"}
+	db := &database{}
+	q := db.NamedModelQuery("UPDATE test_model SET name = :name WHERE id = :id", tm)
+
+	if q.err != nil {
+		t.Errorf("q.err = %v, want <nil>", q.err)
+		return
+	}
+
+	wantStmt := "UPDATE test_model SET name = $1 WHERE id = $2"
+	if q.stmt != wantStmt {
+		t.Errorf("q.stmt = %s, want %s", q.stmt, wantStmt)
+	}
+
+	if len(q.params) != 2 || q.params[0] != "Donkey Hote" || q.params[1] != 1 {
+		t.Errorf("q.params = %v, want [Donkey Hote 1]", q.params)
+	}
+}
@@ -0,0 +1,247 @@
+package kin
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SortKey is a column that a paginated query is sorted by. Together, the sort
+// keys must uniquely identify a row, so the last key is usually the primary
+// key.
+type SortKey struct {
+	// Column is the name of the column in the query's result set.
+	Column string
+
+	// Desc sorts the column in descending order.
+	Desc bool
+}
+
+// Page is a page of rows returned by Paginate.
+type Page struct {
+	Rows []*RowResult
+
+	// Next is the cursor for the page after this one. It's empty when this is
+	// the last page.
+	Next string
+
+	// Prev is the cursor for the page before this one. It's empty when this
+	// is the first page.
+	Prev string
+}
+
+// pageCursor is the content of a cursor: the sort key values of the row that
+// the page starts after, or ends before.
+type pageCursor struct {
+	Before bool     `json:"b,omitempty"`
+	Values []string `json:"v"`
+}
+
+// Paginate executes the query a page at a time using keyset pagination. The
+// rows are sorted by the keys and filtered to the ones that follow the
+// cursor, which is empty for the first page, or one of the cursors returned
+// in a previous page. For example, this query:
+//
+//	page, err := db.Query("SELECT * FROM users WHERE team = $1", team).
+//		Paginate([]kin.SortKey{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}}, 50, cursor)
+//
+// is run as:
+//
+//	SELECT * FROM (SELECT * FROM users WHERE team = $1) AS kin_page
+//	WHERE (created_at, id) < ($2, $3)
+//	ORDER BY created_at DESC, id DESC LIMIT $4
+//
+// Unlike pagination with OFFSET, a page is found with the same index lookup
+// no matter how deep it is, and rows that are inserted or deleted while
+// paginating don't shift the following pages. Sort key columns can't be NULL.
+func (q Query) Paginate(keys []SortKey, size int, cursor string) (*Page, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("pagination requires at least one sort key")
+	}
+
+	if size < 1 {
+		return nil, fmt.Errorf("page size must be positive, got %d", size)
+	}
+
+	var after *pageCursor
+	if cursor != "" {
+		var err error
+		if after, err = decodeCursor(cursor, len(keys)); err != nil {
+			return nil, err
+		}
+	}
+
+	stmt, params := pageStmt(q.stmt, q.params, keys, size, after)
+	q.stmt = stmt
+	q.params = params
+
+	res, err := q.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := res.Rows
+	more := len(rows) > size
+	if more {
+		rows = rows[:size]
+	}
+
+	backward := after != nil && after.Before
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	page := &Page{Rows: rows}
+	if len(rows) == 0 {
+		return page, nil
+	}
+
+	// Moving forward from a cursor means there's a page before this one, and
+	// moving backward means there's a page after it.
+	if (backward && more) || (!backward && after != nil) {
+		if page.Prev, err = encodeCursor(rows[0], keys, true); err != nil {
+			return nil, err
+		}
+	}
+
+	if backward || more {
+		if page.Next, err = encodeCursor(rows[len(rows)-1], keys, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+// PaginateContext is like Paginate, but executes the query with the provided
+// context.
+func (q Query) PaginateContext(ctx context.Context, keys []SortKey, size int, cursor string) (*Page, error) {
+	return q.WithContext(ctx).Paginate(keys, size, cursor)
+}
+
+// pageStmt wraps the statement to return the page that follows the cursor,
+// plus one more row to find out whether there's another page. A cursor that
+// moves backward reverses the sort order, so the rows need to be reversed
+// once they're read.
+func pageStmt(stmt string, params []interface{}, keys []SortKey, size int, after *pageCursor) (string, []interface{}) {
+	inner := strings.TrimRight(strings.TrimSpace(stmt), "; \t\n")
+	backward := after != nil && after.Before
+
+	// Copy the parameters so that the query's aren't modified.
+	bound := make([]interface{}, len(params), len(params)+len(keys)+1)
+	copy(bound, params)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "SELECT * FROM (%s) AS kin_page", inner)
+
+	if after != nil {
+		positions := make([]string, len(keys))
+		for i, value := range after.Values {
+			bound = append(bound, value)
+			positions[i] = fmt.Sprintf("$%d", len(bound))
+		}
+
+		b.WriteString(" WHERE " + keysetCondition(keys, positions, backward))
+	}
+
+	order := make([]string, len(keys))
+	for i, key := range keys {
+		order[i] = key.Column
+		if key.Desc != backward {
+			order[i] += " DESC"
+		}
+	}
+
+	bound = append(bound, size+1)
+	fmt.Fprintf(&b, " ORDER BY %s LIMIT $%d", strings.Join(order, ", "), len(bound))
+
+	return b.String(), bound
+}
+
+// keysetCondition matches the rows that sort after the placeholders' values,
+// or before them when moving backward. When all of the keys are sorted in the
+// same direction, it's a single row comparison that Postgres can answer with
+// an index on the keys. Otherwise each key is compared in turn.
+func keysetCondition(keys []SortKey, positions []string, backward bool) string {
+	operator := func(key SortKey) string {
+		if key.Desc != backward {
+			return "<"
+		}
+		return ">"
+	}
+
+	uniform := true
+	for _, key := range keys[1:] {
+		if key.Desc != keys[0].Desc {
+			uniform = false
+			break
+		}
+	}
+
+	if uniform {
+		columns := make([]string, len(keys))
+		for i, key := range keys {
+			columns[i] = key.Column
+		}
+
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), operator(keys[0]), strings.Join(positions, ", "))
+	}
+
+	terms := make([]string, len(keys))
+	for i, key := range keys {
+		var term []string
+		for j := 0; j < i; j++ {
+			term = append(term, fmt.Sprintf("%s = %s", keys[j].Column, positions[j]))
+		}
+		term = append(term, fmt.Sprintf("%s %s %s", key.Column, operator(key), positions[i]))
+		terms[i] = "(" + strings.Join(term, " AND ") + ")"
+	}
+
+	return "(" + strings.Join(terms, " OR ") + ")"
+}
+
+func encodeCursor(row *RowResult, keys []SortKey, before bool) (string, error) {
+	c := pageCursor{Before: before, Values: make([]string, len(keys))}
+	for i, key := range keys {
+		raw, err := row.extractColumn(key.Column)
+		if err != nil {
+			return "", err
+		}
+
+		if raw == nil {
+			return "", fmt.Errorf("sort key %s is NULL and can't be used in a cursor", key.Column)
+		}
+
+		c.Values[i] = string(raw)
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(cursor string, keys int) (*pageCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c pageCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if len(c.Values) != keys {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
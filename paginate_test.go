@@ -0,0 +1,184 @@
+package kin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestPageStmt(t *testing.T) {
+	keys := []SortKey{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}}
+	params := []interface{}{"kin"}
+
+	tests := []struct {
+		after      *pageCursor
+		wantStmt   string
+		wantParams []interface{}
+	}{
+		{
+			after:      nil,
+			wantStmt:   "SELECT * FROM (SELECT * FROM users WHERE team = $1) AS kin_page ORDER BY created_at DESC, id DESC LIMIT $2",
+			wantParams: []interface{}{"kin", 11},
+		},
+		{
+			after:      &pageCursor{Values: []string{"2018-01-01", "7"}},
+			wantStmt:   "SELECT * FROM (SELECT * FROM users WHERE team = $1) AS kin_page WHERE (created_at, id) < ($2, $3) ORDER BY created_at DESC, id DESC LIMIT $4",
+			wantParams: []interface{}{"kin", "2018-01-01", "7", 11},
+		},
+		{
+			after:      &pageCursor{Before: true, Values: []string{"2018-01-01", "7"}},
+			wantStmt:   "SELECT * FROM (SELECT * FROM users WHERE team = $1) AS kin_page WHERE (created_at, id) > ($2, $3) ORDER BY created_at, id LIMIT $4",
+			wantParams: []interface{}{"kin", "2018-01-01", "7", 11},
+		},
+	}
+
+	for _, test := range tests {
+		stmt, bound := pageStmt("SELECT * FROM users WHERE team = $1;", params, keys, 10, test.after)
+		if stmt != test.wantStmt {
+			t.Errorf("pageStmt(...) = %q, want %q", stmt, test.wantStmt)
+		}
+
+		if len(bound) != len(test.wantParams) {
+			t.Errorf("pageStmt(...) params = %v, want %v", bound, test.wantParams)
+			continue
+		}
+
+		for i, param := range bound {
+			if param != test.wantParams[i] {
+				t.Errorf("pageStmt(...) params[%d] = %v, want %v", i, param, test.wantParams[i])
+			}
+		}
+	}
+
+	if len(params) != 1 {
+		t.Errorf("pageStmt(...) modified params = %v, want [kin]", params)
+	}
+}
+
+func TestKeysetConditionMixed(t *testing.T) {
+	keys := []SortKey{{Column: "name"}, {Column: "id", Desc: true}}
+	positions := []string{"$1", "$2"}
+
+	want := "((name > $1) OR (name = $1 AND id < $2))"
+	if cond := keysetCondition(keys, positions, false); cond != want {
+		t.Errorf("keysetCondition(...) = %q, want %q", cond, want)
+	}
+
+	want = "((name < $1) OR (name = $1 AND id > $2))"
+	if cond := keysetCondition(keys, positions, true); cond != want {
+		t.Errorf("keysetCondition(..., true) = %q, want %q", cond, want)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	keys := []SortKey{{Column: "name"}, {Column: "id"}}
+	rr := newTestRowResult(map[string][]byte{
+		"id":   []byte("7"),
+		"name": []byte("Donkey Hote"),
+	})
+
+	cursor, err := encodeCursor(rr, keys, true)
+	if err != nil {
+		t.Errorf("encodeCursor(...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	c, err := decodeCursor(cursor, len(keys))
+	if err != nil {
+		t.Errorf("decodeCursor(%s) = (_, %v), want (_, <nil>)", cursor, err)
+		return
+	}
+
+	if !c.Before || len(c.Values) != 2 || c.Values[0] != "Donkey Hote" || c.Values[1] != "7" {
+		t.Errorf("decodeCursor(%s) = %+v, want {Before:true Values:[Donkey Hote 7]}", cursor, c)
+	}
+
+	if _, err := decodeCursor(cursor, 1); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("decodeCursor(%s, 1) = (_, %v), want (_, %v)", cursor, err, ErrInvalidCursor)
+	}
+
+	if _, err := decodeCursor("not a cursor", 2); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("decodeCursor(...) = (_, %v), want (_, %v)", err, ErrInvalidCursor)
+	}
+
+	null := newTestRowResult(map[string][]byte{"id": []byte("7"), "name": nil})
+	if _, err := encodeCursor(null, keys, false); err == nil {
+		t.Error("encodeCursor(NULL key) = (_, <nil>), want (_, error)")
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, err := NewConnection(connStr)
+	if err != nil {
+		t.Errorf("NewConnection(%s) = %v, want <nil>", connStr, err)
+		return
+	}
+	defer db.Close()
+
+	// Sorted by grp ascending and id descending, the rows are:
+	// 9, 6, 3, 10, 7, 4, 1, 8, 5, 2.
+	query := db.Query("SELECT g % 3 AS grp, g AS id FROM generate_series(1, $1::int) AS g", 10)
+	keys := []SortKey{{Column: "grp"}, {Column: "id", Desc: true}}
+
+	ids := func(page *Page) []int {
+		var ids []int
+		for _, row := range page.Rows {
+			ids = append(ids, row.ExtractInt("id"))
+		}
+		return ids
+	}
+
+	forward := [][]int{{9, 6, 3}, {10, 7, 4}, {1, 8, 5}, {2}}
+	var page *Page
+	cursor := ""
+	for i, want := range forward {
+		if page, err = query.Paginate(keys, 3, cursor); err != nil {
+			t.Errorf("query.Paginate(..., %q) = (_, %v), want (_, <nil>)", cursor, err)
+			return
+		}
+
+		if got := ids(page); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("forward page %d = %v, want %v", i, got, want)
+		}
+
+		if (page.Prev == "") != (i == 0) {
+			t.Errorf("forward page %d Prev = %q, want it empty only on the first page", i, page.Prev)
+		}
+
+		if (page.Next == "") != (i == len(forward)-1) {
+			t.Errorf("forward page %d Next = %q, want it empty only on the last page", i, page.Next)
+		}
+
+		cursor = page.Next
+	}
+
+	backward := [][]int{{1, 8, 5}, {10, 7, 4}, {9, 6, 3}}
+	cursor = page.Prev
+	for i, want := range backward {
+		if page, err = query.Paginate(keys, 3, cursor); err != nil {
+			t.Errorf("query.Paginate(..., %q) = (_, %v), want (_, <nil>)", cursor, err)
+			return
+		}
+
+		if got := ids(page); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("backward page %d = %v, want %v", i, got, want)
+		}
+
+		if page.Next == "" {
+			t.Errorf("backward page %d Next is empty, want a cursor", i)
+		}
+
+		if (page.Prev == "") != (i == len(backward)-1) {
+			t.Errorf("backward page %d Prev = %q, want it empty only on the first page", i, page.Prev)
+		}
+
+		cursor = page.Prev
+	}
+}
@@ -0,0 +1,140 @@
+package kin
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// In marks a slice parameter to be expanded into a list of parameters, one per
+// element, for use with IN. For example, this query:
+//
+//	db.Query("SELECT * FROM users WHERE id IN ($1)", kin.In([]int{1, 2, 3}))
+//
+// is run as:
+//
+//	SELECT * FROM users WHERE id IN ($1, $2, $3)
+//
+// An empty slice returns ErrEmptyIn when the query runs. There's no empty
+// list in SQL, and standing in NULL for it would turn x NOT IN ($1) into a
+// condition that matches no rows instead of every row, so callers need to
+// handle empty lists themselves. Slices that aren't marked with In are sent as
+// Postgres arrays instead, for use with operators such as = ANY($1), which
+// works with empty arrays.
+func In(values interface{}) interface{} {
+	return inList{values}
+}
+
+type inList struct {
+	values interface{}
+}
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// bindParams adapts the query's parameters before they're sent to the
+// database: lists marked with In are expanded in the statement, and other
+// slices are converted to Postgres arrays.
+func bindParams(stmt string, params []interface{}) (string, []interface{}, error) {
+	expand := false
+	for _, param := range params {
+		if _, ok := param.(inList); ok {
+			expand = true
+			break
+		}
+	}
+
+	if !expand {
+		return stmt, adaptParams(params), nil
+	}
+
+	// Map each original position to the positions that replace it.
+	var bound []interface{}
+	positions := make([]string, len(params))
+	for i, param := range params {
+		list, ok := param.(inList)
+		if !ok {
+			bound = append(bound, param)
+			positions[i] = fmt.Sprintf("$%d", len(bound))
+			continue
+		}
+
+		rv := reflect.ValueOf(list.values)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return "", nil, fmt.Errorf("parameter $%d must be a slice to be used with In, got %T", i+1, list.values)
+		}
+
+		if rv.Len() == 0 {
+			return "", nil, fmt.Errorf("%w: parameter $%d", ErrEmptyIn, i+1)
+		}
+
+		expanded := make([]string, rv.Len())
+		for j := 0; j < rv.Len(); j++ {
+			bound = append(bound, rv.Index(j).Interface())
+			expanded[j] = fmt.Sprintf("$%d", len(bound))
+		}
+		positions[i] = strings.Join(expanded, ", ")
+	}
+
+	rewritten, err := rewritePlaceholders(stmt, func(p placeholder) (string, error) {
+		if p.name != "" {
+			return p.raw, nil
+		}
+
+		if p.position < 1 || p.position > len(positions) {
+			return "", fmt.Errorf("parameter %s has no value", p)
+		}
+
+		return positions[p.position-1], nil
+	})
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return rewritten, adaptParams(bound), nil
+}
+
+// adaptParams converts slice parameters to Postgres arrays. Byte slices of
+// any type and values that implement driver.Valuer are left alone.
+func adaptParams(params []interface{}) []interface{} {
+	var adapted []interface{}
+	for i, param := range params {
+		if !isArrayParam(param) {
+			continue
+		}
+
+		if adapted == nil {
+			adapted = make([]interface{}, len(params))
+			copy(adapted, params)
+		}
+		adapted[i] = pq.Array(param)
+	}
+
+	if adapted == nil {
+		return params
+	}
+
+	return adapted
+}
+
+func isArrayParam(param interface{}) bool {
+	if param == nil {
+		return false
+	}
+
+	t := reflect.TypeOf(param)
+	if t.Implements(valuerType) {
+		return false
+	}
+
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return false
+	}
+
+	// Byte slices, including named types such as json.RawMessage and net.IP,
+	// are sent as they are.
+	return t.Elem().Kind() != reflect.Uint8
+}
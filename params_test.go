@@ -0,0 +1,103 @@
+package kin
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestBindParamsIn(t *testing.T) {
+	stmt, params, err := bindParams(
+		"SELECT * FROM users WHERE name = $1 AND id IN ($2) AND status = $3::text AND note <> '$2'",
+		[]interface{}{"Donkey Hote", In([]int{4, 5}), "active"},
+	)
+
+	if err != nil {
+		t.Errorf("bindParams(...) = (_, _, %v), want (_, _, <nil>)", err)
+		return
+	}
+
+	wantStmt := "SELECT * FROM users WHERE name = $1 AND id IN ($2, $3) AND status = $4::text AND note <> '$2'"
+	if stmt != wantStmt {
+		t.Errorf("bindParams(...) = %q, want %q", stmt, wantStmt)
+	}
+
+	wantParams := []interface{}{"Donkey Hote", 4, 5, "active"}
+	if len(params) != len(wantParams) {
+		t.Errorf("bindParams(...) params = %v, want %v", params, wantParams)
+		return
+	}
+
+	for i, param := range params {
+		if param != wantParams[i] {
+			t.Errorf("bindParams(...) params[%d] = %v, want %v", i, param, wantParams[i])
+		}
+	}
+}
+
+func TestBindParamsInEmpty(t *testing.T) {
+	// An empty list can't stand in as NULL, since NOT IN (NULL) matches no
+	// rows instead of every row.
+	stmts := []string{
+		"SELECT * FROM users WHERE id IN ($1)",
+		"SELECT * FROM users WHERE id NOT IN ($1)",
+	}
+
+	for _, stmt := range stmts {
+		if _, _, err := bindParams(stmt, []interface{}{In([]int{})}); !errors.Is(err, ErrEmptyIn) {
+			t.Errorf("bindParams(%q, In([]int{})) = (_, _, %v), want (_, _, %v)", stmt, err, ErrEmptyIn)
+		}
+	}
+
+	if _, _, err := bindParams("SELECT $1", []interface{}{In(5)}); err == nil {
+		t.Error("bindParams(_, In(5)) = (_, _, <nil>), want (_, _, error)")
+	}
+}
+
+func TestBindParamsArrays(t *testing.T) {
+	_, params, err := bindParams("SELECT * FROM users WHERE id = ANY($1) AND data = $2", []interface{}{[]int{1, 2}, []byte("raw")})
+	if err != nil {
+		t.Errorf("bindParams(...) = (_, _, %v), want (_, _, <nil>)", err)
+		return
+	}
+
+	valuer, ok := params[0].(driver.Valuer)
+	if !ok {
+		t.Errorf("params[0] = %T, want driver.Valuer", params[0])
+		return
+	}
+
+	if value, err := valuer.Value(); err != nil || value != "{1,2}" {
+		t.Errorf("params[0].Value() = (%v, %v), want ({1,2}, <nil>)", value, err)
+	}
+
+	if _, ok := params[1].([]byte); !ok {
+		t.Errorf("params[1] = %T, want []byte", params[1])
+	}
+
+	strings := pq.StringArray{"a"}
+	if _, params, _ := bindParams("SELECT $1", []interface{}{strings}); params[0].(pq.StringArray)[0] != "a" {
+		t.Errorf("params[0] = %T, want pq.StringArray", params[0])
+	}
+}
+
+func TestBindParamsByteSlices(t *testing.T) {
+	raw := json.RawMessage(`{"a": 1}`)
+	_, params, err := bindParams("INSERT INTO docs (data, ip) VALUES ($1, $2)", []interface{}{raw, net.ParseIP("127.0.0.1")})
+	if err != nil {
+		t.Errorf("bindParams(...) = (_, _, %v), want (_, _, <nil>)", err)
+		return
+	}
+
+	if _, ok := params[0].(json.RawMessage); !ok {
+		t.Errorf("params[0] = %T, want json.RawMessage", params[0])
+	}
+
+	if _, ok := params[1].(net.IP); !ok {
+		t.Errorf("params[1] = %T, want net.IP", params[1])
+	}
+}
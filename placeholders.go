@@ -0,0 +1,177 @@
+package kin
+
+import (
+	"strconv"
+	"strings"
+)
+
+// placeholder is a parameter placeholder found in a SQL statement. Positional
+// placeholders ($1) have a position and named placeholders (:name or @name)
+// have a name.
+type placeholder struct {
+	position int
+	name     string
+
+	// raw is the placeholder as it was written in the statement.
+	raw string
+}
+
+func (p placeholder) String() string {
+	return p.raw
+}
+
+// rewritePlaceholders copies the statement, replacing every placeholder with
+// the result of replace. Anything inside of string literals, quoted
+// identifiers, dollar-quoted strings and comments is copied as is, as are
+// type casts such as ::int.
+func rewritePlaceholders(stmt string, replace func(placeholder) (string, error)) (string, error) {
+	var out strings.Builder
+	out.Grow(len(stmt))
+
+	for i := 0; i < len(stmt); {
+		c := stmt[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := quotedEnd(stmt, i, c)
+			out.WriteString(stmt[i:end])
+			i = end
+		case c == '-' && strings.HasPrefix(stmt[i:], "--"):
+			end := strings.IndexByte(stmt[i:], '\n')
+			if end < 0 {
+				end = len(stmt) - i
+			}
+			out.WriteString(stmt[i : i+end])
+			i += end
+		case c == '/' && strings.HasPrefix(stmt[i:], "/*"):
+			end := blockCommentEnd(stmt, i)
+			out.WriteString(stmt[i:end])
+			i = end
+		case c == ':' && strings.HasPrefix(stmt[i:], "::"):
+			out.WriteString("::")
+			i += 2
+		case c == '$' && !precededByIdent(stmt, i):
+			if n := digitsEnd(stmt, i+1); n > i+1 {
+				position, err := strconv.Atoi(stmt[i+1 : n])
+				if err != nil {
+					return "", err
+				}
+
+				replacement, err := replace(placeholder{position: position, raw: stmt[i:n]})
+				if err != nil {
+					return "", err
+				}
+
+				out.WriteString(replacement)
+				i = n
+				continue
+			}
+
+			end := dollarQuotedEnd(stmt, i)
+			out.WriteString(stmt[i:end])
+			i = end
+		case (c == ':' || c == '@') && !precededByIdent(stmt, i) && i+1 < len(stmt) && isIdentStart(stmt[i+1]):
+			n := i + 1
+			for n < len(stmt) && isIdentChar(stmt[n]) {
+				n++
+			}
+
+			replacement, err := replace(placeholder{name: stmt[i+1 : n], raw: stmt[i:n]})
+			if err != nil {
+				return "", err
+			}
+
+			out.WriteString(replacement)
+			i = n
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+
+	return out.String(), nil
+}
+
+// quotedEnd returns the index just past the string literal or quoted
+// identifier that starts at i. Doubled quotes are treated as escapes, as are
+// backslashes in escape strings such as E'it\'s'.
+func quotedEnd(stmt string, i int, quote byte) int {
+	escapes := quote == '\'' && i > 0 && (stmt[i-1] == 'E' || stmt[i-1] == 'e') && !precededByIdent(stmt, i-1)
+
+	for n := i + 1; n < len(stmt); n++ {
+		switch {
+		case escapes && stmt[n] == '\\':
+			n++
+		case stmt[n] == quote:
+			if n+1 < len(stmt) && stmt[n+1] == quote {
+				n++
+				continue
+			}
+			return n + 1
+		}
+	}
+
+	return len(stmt)
+}
+
+// blockCommentEnd returns the index just past the block comment that starts
+// at i. Postgres allows block comments to be nested.
+func blockCommentEnd(stmt string, i int) int {
+	depth := 0
+	for n := i; n < len(stmt)-1; n++ {
+		switch stmt[n : n+2] {
+		case "/*":
+			depth++
+			n++
+		case "*/":
+			depth--
+			n++
+			if depth == 0 {
+				return n + 1
+			}
+		}
+	}
+
+	return len(stmt)
+}
+
+// dollarQuotedEnd returns the index just past the dollar-quoted string that
+// starts at i, such as $$text$$ or $tag$text$tag$. If there's no valid tag at
+// i, only the dollar sign is consumed.
+func dollarQuotedEnd(stmt string, i int) int {
+	n := i + 1
+	for n < len(stmt) && isIdentChar(stmt[n]) && stmt[n] != '$' {
+		n++
+	}
+
+	if n >= len(stmt) || stmt[n] != '$' || (n > i+1 && !isIdentStart(stmt[i+1])) {
+		return i + 1
+	}
+
+	tag := stmt[i : n+1]
+	end := strings.Index(stmt[n+1:], tag)
+	if end < 0 {
+		return len(stmt)
+	}
+
+	return n + 1 + end + len(tag)
+}
+
+func digitsEnd(stmt string, i int) int {
+	for i < len(stmt) && stmt[i] >= '0' && stmt[i] <= '9' {
+		i++
+	}
+
+	return i
+}
+
+func precededByIdent(stmt string, i int) bool {
+	return i > 0 && isIdentChar(stmt[i-1])
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9') || c == '$'
+}
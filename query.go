@@ -1,22 +1,37 @@
 package kin
 
 import (
+	"context"
 	"database/sql"
-	"errors"
 )
 
 type databaseConnection interface {
-	Prepare(string) (*sql.Stmt, error)
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
 }
 
 // Query is a SQL query that has yet to be executed.
 type Query struct {
+	ctx    context.Context
 	db     databaseConnection
+	tx     *sql.Tx
+	stmts  *stmtCache
 	stmt   string
 	params []interface{}
+
+	// err is set when the query couldn't be generated and is returned when
+	// the query is executed.
+	err error
+}
+
+// WithContext returns a copy of the query that executes with the provided
+// context. Canceling the context aborts the query while it's running.
+func (q Query) WithContext(ctx context.Context) *Query {
+	q.ctx = ctx
+	return &q
 }
 
-// One executes the query and returns an error if no results are found.
+// One executes the query and returns ErrNoRows if no results are found.
 func (q Query) One() (*RowResult, error) {
 	result, err := q.Run()
 	if err != nil {
@@ -24,12 +39,17 @@ func (q Query) One() (*RowResult, error) {
 	}
 
 	if len(result.Rows) < 1 {
-		return nil, errors.New("query must return at least one result")
+		return nil, ErrNoRows
 	}
 
 	return result.Rows[0], nil
 }
 
+// OneContext is like One, but executes the query with the provided context.
+func (q Query) OneContext(ctx context.Context) (*RowResult, error) {
+	return q.WithContext(ctx).One()
+}
+
 // OneAndExtract executes the query and updates the builder with the result.
 func (q Query) OneAndExtract(b Builder) error {
 	res, err := q.One()
@@ -40,6 +60,12 @@ func (q Query) OneAndExtract(b Builder) error {
 	return buildOne(b, res)
 }
 
+// OneAndExtractContext is like OneAndExtract, but executes the query with
+// the provided context.
+func (q Query) OneAndExtractContext(ctx context.Context, b Builder) error {
+	return q.WithContext(ctx).OneAndExtract(b)
+}
+
 // OneAndExtractFn executes the query and returns the model with the result
 // with an extraction function.
 func (q Query) OneAndExtractFn(buildFn func(*RowResult) error) error {
@@ -51,6 +77,101 @@ func (q Query) OneAndExtractFn(buildFn func(*RowResult) error) error {
 	return buildFn(res)
 }
 
+// OneAndExtractFnContext is like OneAndExtractFn, but executes the query with
+// the provided context.
+func (q Query) OneAndExtractFnContext(ctx context.Context, buildFn func(*RowResult) error) error {
+	return q.WithContext(ctx).OneAndExtractFn(buildFn)
+}
+
+// ExactlyOne executes the query and returns its only row. It returns
+// ErrNoRows if no rows are found and ErrTooManyRows if more than one is.
+func (q Query) ExactlyOne() (*RowResult, error) {
+	res, found, err := q.MaybeOne()
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, ErrNoRows
+	}
+
+	return res, nil
+}
+
+// ExactlyOneContext is like ExactlyOne, but executes the query with the
+// provided context.
+func (q Query) ExactlyOneContext(ctx context.Context) (*RowResult, error) {
+	return q.WithContext(ctx).ExactlyOne()
+}
+
+// ExactlyOneAndExtract executes the query and updates the builder with its
+// only row. It returns ErrNoRows if no rows are found and ErrTooManyRows if
+// more than one is.
+func (q Query) ExactlyOneAndExtract(b Builder) error {
+	res, err := q.ExactlyOne()
+	if err != nil {
+		return err
+	}
+
+	return buildOne(b, res)
+}
+
+// ExactlyOneAndExtractContext is like ExactlyOneAndExtract, but executes the
+// query with the provided context.
+func (q Query) ExactlyOneAndExtractContext(ctx context.Context, b Builder) error {
+	return q.WithContext(ctx).ExactlyOneAndExtract(b)
+}
+
+// MaybeOne executes the query and returns its row, if there is one. The
+// returned flag reports whether a row was found. It returns ErrTooManyRows if
+// the query returns more than one row.
+func (q Query) MaybeOne() (*RowResult, bool, error) {
+	rows, err := q.Stream()
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, false, rows.Err()
+	}
+
+	res := rows.Row()
+	if rows.Next() {
+		return nil, false, ErrTooManyRows
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	return res, true, nil
+}
+
+// MaybeOneContext is like MaybeOne, but executes the query with the provided
+// context.
+func (q Query) MaybeOneContext(ctx context.Context) (*RowResult, bool, error) {
+	return q.WithContext(ctx).MaybeOne()
+}
+
+// MaybeOneAndExtract executes the query and updates the builder with its row,
+// if there is one. The returned flag reports whether a row was found. It
+// returns ErrTooManyRows if the query returns more than one row.
+func (q Query) MaybeOneAndExtract(b Builder) (bool, error) {
+	res, found, err := q.MaybeOne()
+	if err != nil || !found {
+		return false, err
+	}
+
+	return true, buildOne(b, res)
+}
+
+// MaybeOneAndExtractContext is like MaybeOneAndExtract, but executes the query
+// with the provided context.
+func (q Query) MaybeOneAndExtractContext(ctx context.Context, b Builder) (bool, error) {
+	return q.WithContext(ctx).MaybeOneAndExtract(b)
+}
+
 // ExtractFn executes the query and iterates over all rows to extract
 // the result.
 func (q Query) ExtractFn(buildFn func(*RowResult) error) error {
@@ -68,17 +189,204 @@ func (q Query) ExtractFn(buildFn func(*RowResult) error) error {
 	return nil
 }
 
+// ExtractFnContext is like ExtractFn, but executes the query with the
+// provided context.
+func (q Query) ExtractFnContext(ctx context.Context, buildFn func(*RowResult) error) error {
+	return q.WithContext(ctx).ExtractFn(buildFn)
+}
+
+// StreamFn executes the query and hands each row to the extraction function
+// as it's read from the database, without buffering the result set. The
+// underlying rows are released before StreamFn returns.
+func (q Query) StreamFn(buildFn func(*RowResult) error) error {
+	rows, err := q.Stream()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := buildFn(rows.Row()); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// StreamFnContext is like StreamFn, but executes the query with the provided
+// context.
+func (q Query) StreamFnContext(ctx context.Context, buildFn func(*RowResult) error) error {
+	return q.WithContext(ctx).StreamFn(buildFn)
+}
+
+// Stream executes the query and returns an iterator over its rows. The caller
+// is responsible for closing the iterator.
+func (q Query) Stream() (*Rows, error) {
+	ctx := q.context()
+
+	rows, err := q.query(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRows(ctx, rows)
+}
+
+// StreamContext is like Stream, but executes the query with the provided
+// context.
+func (q Query) StreamContext(ctx context.Context) (*Rows, error) {
+	return q.WithContext(ctx).Stream()
+}
+
 // Run executes the query and returns the results.
 func (q Query) Run() (*Result, error) {
-	stmt, err := q.db.Prepare(q.stmt)
+	ctx := q.context()
+
+	rows, err := q.query(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := newResult(rows)
+	if err != nil {
+		return nil, wrapError(ctx, err)
+	}
+
+	return res, nil
+}
+
+// RunContext is like Run, but executes the query with the provided context.
+func (q Query) RunContext(ctx context.Context) (*Result, error) {
+	return q.WithContext(ctx).Run()
+}
+
+// Exec executes the query without reading any rows that it returns.
+func (q Query) Exec() (*ExecResult, error) {
+	ctx := q.context()
+
+	query, params, err := q.bind()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, release, err := q.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if stmt == nil {
+		return exec(ctx, q.db, query, params...)
+	}
+	defer release()
+
+	res, err := stmt.ExecContext(ctx, params...)
+	if err != nil {
+		return nil, wrapError(ctx, err)
+	}
+
+	return newExecResult(query, res), nil
+}
+
+// ExecContext is like Exec, but executes the query with the provided context.
+func (q Query) ExecContext(ctx context.Context) (*ExecResult, error) {
+	return q.WithContext(ctx).Exec()
+}
+
+// ExecRequireRows is like Exec, but returns ErrNoRows when the query is an
+// INSERT, UPDATE or DELETE that didn't affect any rows. This is useful when
+// updating or deleting a row that's expected to exist.
+func (q Query) ExecRequireRows() (*ExecResult, error) {
+	res, err := q.Exec()
+	if err != nil {
+		return nil, err
+	}
+
+	return res, res.requireRows()
+}
+
+// ExecRequireRowsContext is like ExecRequireRows, but executes the query with
+// the provided context.
+func (q Query) ExecRequireRowsContext(ctx context.Context) (*ExecResult, error) {
+	return q.WithContext(ctx).ExecRequireRows()
+}
+
+func (q Query) query(ctx context.Context) (*sql.Rows, error) {
+	query, params, err := q.bind()
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := stmt.Query(q.params...)
+	stmt, release, err := q.prepare(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	return newResult(rows)
+	if stmt == nil {
+		rows, err := q.db.QueryContext(ctx, query, params...)
+		return rows, wrapError(ctx, err)
+	}
+	defer release()
+
+	rows, err := stmt.QueryContext(ctx, params...)
+	if err != nil {
+		return nil, wrapError(ctx, err)
+	}
+
+	return rows, nil
+}
+
+// bind returns the statement and parameters that are sent to the database,
+// with lists marked by In expanded and slices converted to arrays.
+func (q Query) bind() (string, []interface{}, error) {
+	if q.err != nil {
+		return "", nil, q.err
+	}
+
+	return bindParams(q.stmt, q.params)
+}
+
+// prepare returns the cached prepared statement for the query, bound to the
+// query's transaction if it has one, and a function that releases it once it's
+// been run. It returns a nil statement when prepared statements are disabled.
+func (q Query) prepare(ctx context.Context, query string) (*sql.Stmt, func(), error) {
+	if q.stmts == nil {
+		return nil, func() {}, nil
+	}
+
+	cached, err := q.stmts.prepare(ctx, query)
+	if err != nil {
+		return nil, nil, wrapError(ctx, err)
+	}
+
+	stmt := cached.stmt
+	if q.tx != nil {
+		// Rebind the cached statement to the transaction's connection.
+		stmt = q.tx.StmtContext(ctx, stmt)
+	}
+
+	release := func() {
+		// Closing the rebound statement leaves the cached one open, and any
+		// rows that are still being read keep it alive until they're closed.
+		if q.tx != nil {
+			stmt.Close()
+		}
+
+		q.stmts.release(cached)
+	}
+
+	return stmt, release, nil
+}
+
+func (q *Query) withErr(err error) *Query {
+	q.err = err
+	return q
+}
+
+func (q Query) context() context.Context {
+	if q.ctx == nil {
+		return context.Background()
+	}
+
+	return q.ctx
 }
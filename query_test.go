@@ -0,0 +1,161 @@
+package kin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/jmataya/renv/autoload"
+)
+
+func TestQueryRunContextCanceled(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, err := NewConnection(connStr)
+	if err != nil {
+		t.Errorf("NewConnection(%s) = %v, want <nil>", connStr, err)
+		return
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = db.Query("SELECT pg_sleep(5)").RunContext(ctx)
+	if !errors.Is(err, ErrCanceled) {
+		t.Errorf("db.Query(...).RunContext(...) = %v, want %v", err, ErrCanceled)
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("db.Query(...).RunContext(...) = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWrapErrorCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	driverErr := errors.New("pq: canceling statement due to user request")
+	if err := wrapError(ctx, driverErr); err != driverErr {
+		t.Errorf("wrapError(...) = %v, want %v", err, driverErr)
+	}
+
+	if err := wrapError(ctx, nil); err != nil {
+		t.Errorf("wrapError(ctx, <nil>) = %v, want <nil>", err)
+	}
+
+	cancel()
+
+	err := wrapError(ctx, driverErr)
+	if !errors.Is(err, ErrCanceled) {
+		t.Errorf("wrapError(...) = %v, want %v", err, ErrCanceled)
+	}
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("wrapError(...) = %v, want %v", err, context.Canceled)
+	}
+
+	want := "database operation canceled: context canceled"
+	if err := wrapError(ctx, context.Canceled); err.Error() != want {
+		t.Errorf("wrapError(ctx, context.Canceled) = %v, want %s", err, want)
+	}
+}
+
+func TestQueryStream(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, err := NewConnection(connStr)
+	if err != nil {
+		t.Errorf("NewConnection(%s) = %v, want <nil>", connStr, err)
+		return
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT generate_series(1, $1) AS n", 3).Stream()
+	if err != nil {
+		t.Errorf("db.Query(...).Stream() = %v, want <nil>", err)
+		return
+	}
+	defer rows.Close()
+
+	want := 1
+	for rows.Next() {
+		if got := rows.Row().ExtractInt("n"); got != want {
+			t.Errorf("rows.Row().ExtractInt(\"n\") = %d, want %d", got, want)
+		}
+		want++
+	}
+
+	if err := rows.Err(); err != nil {
+		t.Errorf("rows.Err() = %v, want <nil>", err)
+	}
+
+	if want != 4 {
+		t.Errorf("rows.Next() iterated %d times, want 3", want-1)
+	}
+
+	if rows.Next() {
+		t.Error("rows.Next() = true after exhausting rows, want false")
+	}
+}
+
+func TestQueryExactlyOneAndMaybeOne(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, err := NewConnection(connStr)
+	if err != nil {
+		t.Errorf("NewConnection(%s) = %v, want <nil>", connStr, err)
+		return
+	}
+	defer db.Close()
+
+	stmt := "SELECT generate_series(1, $1) AS n"
+
+	if _, err := db.Query(stmt, 0).ExactlyOne(); !errors.Is(err, ErrNoRows) {
+		t.Errorf("db.Query(0 rows).ExactlyOne() = (_, %v), want (_, %v)", err, ErrNoRows)
+	}
+
+	if _, err := db.Query(stmt, 2).ExactlyOne(); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("db.Query(2 rows).ExactlyOne() = (_, %v), want (_, %v)", err, ErrTooManyRows)
+	}
+
+	if res, err := db.Query(stmt, 1).ExactlyOne(); err != nil || res.ExtractInt("n") != 1 {
+		t.Errorf("db.Query(1 row).ExactlyOne() = (_, %v), want (1, <nil>)", err)
+	}
+
+	if _, found, err := db.Query(stmt, 0).MaybeOne(); found || err != nil {
+		t.Errorf("db.Query(0 rows).MaybeOne() = (_, %v, %v), want (_, false, <nil>)", found, err)
+	}
+
+	if _, found, err := db.Query(stmt, 1).MaybeOne(); !found || err != nil {
+		t.Errorf("db.Query(1 row).MaybeOne() = (_, %v, %v), want (_, true, <nil>)", found, err)
+	}
+
+	if _, _, err := db.Query(stmt, 2).MaybeOne(); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("db.Query(2 rows).MaybeOne() = (_, _, %v), want (_, _, %v)", err, ErrTooManyRows)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := db.Query(stmt, 1).ExactlyOneContext(ctx); !errors.Is(err, ErrCanceled) {
+		t.Errorf("db.Query(1 row).ExactlyOneContext(canceled) = (_, %v), want (_, %v)", err, ErrCanceled)
+	}
+
+	if _, _, err := db.Query(stmt, 1).MaybeOneContext(ctx); !errors.Is(err, ErrCanceled) {
+		t.Errorf("db.Query(1 row).MaybeOneContext(canceled) = (_, _, %v), want (_, _, %v)", err, ErrCanceled)
+	}
+}
@@ -101,6 +101,141 @@ func (rr *RowResult) ExtractString(column string) string {
 	return string(rawCol)
 }
 
+// ExtractNullJSON gets a JSON value that may be NULL from the dataset and
+// unmarshals it into an interface passed by the caller. A NULL value is
+// unmarshalled as JSON null, which sets pointers, maps and slices to nil.
+func (rr *RowResult) ExtractNullJSON(column string, out interface{}) {
+	isNull, err := rr.isNull(column)
+	if err != nil {
+		rr.err = err
+		return
+	}
+
+	if !isNull {
+		rr.ExtractJSON(column, out)
+		return
+	}
+
+	if err := json.Unmarshal([]byte("null"), out); err != nil {
+		rr.err = fmt.Errorf("column %s could not be unmarshalled with err %v", column, err)
+	}
+}
+
+// ExtractNullBool gets the value in the dataset and returns a boolean, or nil
+// if the value is NULL.
+// If the value can't be extracted, it stores an error on the result and
+// prevents further extraction from occurring.
+func (rr *RowResult) ExtractNullBool(column string) *bool {
+	isNull, err := rr.isNull(column)
+	if err != nil {
+		rr.err = err
+		return nil
+	}
+
+	if isNull {
+		return nil
+	}
+
+	b := rr.ExtractBool(column)
+	if rr.err != nil {
+		return nil
+	}
+
+	return &b
+}
+
+// ExtractNullDecimal gets the value in the dataset and returns a float, or nil
+// if the value is NULL.
+// If the value can't be extracted, it stores an error on the result and
+// prevents further extraction from occurring.
+func (rr *RowResult) ExtractNullDecimal(column string) *float64 {
+	isNull, err := rr.isNull(column)
+	if err != nil {
+		rr.err = err
+		return nil
+	}
+
+	if isNull {
+		return nil
+	}
+
+	num := rr.ExtractDecimal(column)
+	if rr.err != nil {
+		return nil
+	}
+
+	return &num
+}
+
+// ExtractNullInt gets the value in the dataset and returns an integer, or nil
+// if the value is NULL.
+// If the value can't be extracted, it stores an error on the result and
+// prevents further extraction from occurring.
+func (rr *RowResult) ExtractNullInt(column string) *int {
+	isNull, err := rr.isNull(column)
+	if err != nil {
+		rr.err = err
+		return nil
+	}
+
+	if isNull {
+		return nil
+	}
+
+	num := rr.ExtractInt(column)
+	if rr.err != nil {
+		return nil
+	}
+
+	return &num
+}
+
+// ExtractNullString gets the value in the dataset and returns a string, or
+// nil if the value is NULL.
+// If the value can't be extracted, it stores an error on the result and
+// prevents further extraction from occurring.
+func (rr *RowResult) ExtractNullString(column string) *string {
+	isNull, err := rr.isNull(column)
+	if err != nil {
+		rr.err = err
+		return nil
+	}
+
+	if isNull {
+		return nil
+	}
+
+	str := rr.ExtractString(column)
+	if rr.err != nil {
+		return nil
+	}
+
+	return &str
+}
+
+// ExtractNullTime gets the value in the dataset and returns a time.Time, or
+// nil if the value is NULL.
+// If the value can't be extracted, it stores an error on the result and
+// prevents further extraction from occurring.
+func (rr *RowResult) ExtractNullTime(column string) *time.Time {
+	isNull, err := rr.isNull(column)
+	if err != nil {
+		rr.err = err
+		return nil
+	}
+
+	if isNull {
+		return nil
+	}
+
+	t := rr.ExtractTime(column)
+	if rr.err != nil {
+		return nil
+	}
+
+	return &t
+}
+
 // Err returns any aggregrated errors.
 func (rr *RowResult) Err() error {
 	return rr.err
@@ -154,6 +289,17 @@ func (rr *RowResult) ExtractTime(column string) time.Time {
 	return t
 }
 
+// isNull reports whether the column's value is NULL. The driver scans NULL
+// values as a nil byte slice, while empty values are non-nil.
+func (rr *RowResult) isNull(column string) (bool, error) {
+	rawCol, err := rr.extractColumn(column)
+	if err != nil {
+		return false, err
+	}
+
+	return rawCol == nil, nil
+}
+
 func (rr *RowResult) extractColumn(column string) ([]byte, error) {
 	if rr.err != nil {
 		return nil, rr.err
@@ -1,5 +1,113 @@
 package kin
 
+import (
+	"testing"
+)
+
+func newTestRowResult(data map[string][]byte) *RowResult {
+	rr := &RowResult{Data: map[string]interface{}{}}
+	for column, value := range data {
+		v := value
+		rr.Columns = append(rr.Columns, column)
+		rr.Data[column] = &v
+	}
+
+	return rr
+}
+
+func TestExtractNull(t *testing.T) {
+	rr := newTestRowResult(map[string][]byte{
+		"id":         nil,
+		"name":       nil,
+		"is_active":  nil,
+		"score":      nil,
+		"created_at": nil,
+		"attributes": nil,
+	})
+
+	if v := rr.ExtractNullInt("id"); v != nil {
+		t.Errorf("rr.ExtractNullInt(\"id\") = %v, want <nil>", *v)
+	}
+
+	if v := rr.ExtractNullString("name"); v != nil {
+		t.Errorf("rr.ExtractNullString(\"name\") = %v, want <nil>", *v)
+	}
+
+	if v := rr.ExtractNullBool("is_active"); v != nil {
+		t.Errorf("rr.ExtractNullBool(\"is_active\") = %v, want <nil>", *v)
+	}
+
+	if v := rr.ExtractNullDecimal("score"); v != nil {
+		t.Errorf("rr.ExtractNullDecimal(\"score\") = %v, want <nil>", *v)
+	}
+
+	if v := rr.ExtractNullTime("created_at"); v != nil {
+		t.Errorf("rr.ExtractNullTime(\"created_at\") = %v, want <nil>", *v)
+	}
+
+	attributes := map[string]interface{}{"lang": "en"}
+	if rr.ExtractNullJSON("attributes", &attributes); attributes != nil {
+		t.Errorf("rr.ExtractNullJSON(\"attributes\", ...) = %v, want <nil>", attributes)
+	}
+
+	if err := rr.Err(); err != nil {
+		t.Errorf("rr.Err() = %v, want <nil>", err)
+	}
+}
+
+func TestExtractNullValues(t *testing.T) {
+	rr := newTestRowResult(map[string][]byte{
+		"id":   []byte("0"),
+		"name": []byte(""),
+	})
+
+	if v := rr.ExtractNullInt("id"); v == nil || *v != 0 {
+		t.Errorf("rr.ExtractNullInt(\"id\") = %v, want 0", v)
+	}
+
+	if v := rr.ExtractNullString("name"); v == nil || *v != "" {
+		t.Errorf("rr.ExtractNullString(\"name\") = %v, want \"\"", v)
+	}
+
+	if v := rr.ExtractNullInt("missing"); v != nil || rr.Err() == nil {
+		t.Errorf("rr.ExtractNullInt(\"missing\") = (%v, %v), want (<nil>, error)", v, rr.Err())
+	}
+}
+
+func TestNullFieldGet(t *testing.T) {
+	var id *int
+	var attributes map[string]interface{}
+
+	if v := NullIntField("id", &id).Get(); v != nil {
+		t.Errorf("NullIntField(...).Get() = %v, want <nil>", v)
+	}
+
+	value, err := paramValue(NullJSONField("attributes", &attributes)).(nullJSONValue).Value()
+	if value != nil || err != nil {
+		t.Errorf("paramValue(NullJSONField(...)).Value() = (%v, %v), want (<nil>, <nil>)", value, err)
+	}
+
+	n := 0
+	id = &n
+	if v := NullIntField("id", &id).Get(); v != 0 {
+		t.Errorf("NullIntField(...).Get() = %v, want 0", v)
+	}
+}
+
+func TestJSONFieldParam(t *testing.T) {
+	attributes := map[string]interface{}{"color": "red"}
+	field := JSONField("attributes", &attributes)
+
+	if v, ok := field.Get().(*map[string]interface{}); !ok || v != &attributes {
+		t.Errorf("JSONField(...).Get() = %v, want %v", field.Get(), &attributes)
+	}
+
+	value, err := paramValue(field).(jsonValue).Value()
+	if want := `{"color":"red"}`; value != want || err != nil {
+		t.Errorf("paramValue(JSONField(...)).Value() = (%v, %v), want (%s, <nil>)", value, err, want)
+	}
+}
+
 // func TestRowResult(t *testing.T) {
 // 	getCurrentFile()
 // 	t.Error("Error")
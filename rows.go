@@ -0,0 +1,111 @@
+package kin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var errNoCurrentRow = errors.New("scan called without a successful call to next")
+
+// Rows is a streaming iterator over the results of a query. Unlike Result,
+// it doesn't buffer the result set in memory; each row is read from the
+// database as Next is called.
+//
+// Rows must always be closed once the caller is done with it, otherwise the
+// underlying connection won't be returned to the pool.
+//
+//	rows, err := db.Query("SELECT * FROM users").Stream()
+//	if err != nil {
+//		return err
+//	}
+//	defer rows.Close()
+//
+//	for rows.Next() {
+//		u := new(user)
+//		if err := rows.Scan(u); err != nil {
+//			return err
+//		}
+//	}
+//
+//	return rows.Err()
+type Rows struct {
+	ctx     context.Context
+	rows    *sql.Rows
+	columns []string
+	current *RowResult
+	err     error
+}
+
+func newRows(ctx context.Context, rows *sql.Rows) (*Rows, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, wrapError(ctx, err)
+	}
+
+	return &Rows{ctx: ctx, rows: rows, columns: columns}, nil
+}
+
+// Columns returns the names of the columns in the result set.
+func (r *Rows) Columns() []string {
+	return r.columns
+}
+
+// Next advances the iterator to the next row. It returns false when there are
+// no more rows or an error occurred, at which point the rows are closed
+// automatically and Err should be checked.
+func (r *Rows) Next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	r.current = nil
+	if !r.rows.Next() {
+		r.setErr(r.rows.Err())
+		r.rows.Close()
+		return false
+	}
+
+	row, err := newRowResult(r.rows)
+	if err != nil {
+		r.setErr(err)
+		r.rows.Close()
+		return false
+	}
+
+	r.current = row
+	return true
+}
+
+// Row returns the row that the iterator currently points to.
+func (r *Rows) Row() *RowResult {
+	return r.current
+}
+
+// Scan extracts the current row into the builder.
+func (r *Rows) Scan(b Builder) error {
+	if r.current == nil {
+		return errNoCurrentRow
+	}
+
+	return buildOne(b, r.current)
+}
+
+// Err returns the error, if any, that was encountered during iteration.
+func (r *Rows) Err() error {
+	return r.err
+}
+
+// Close releases the underlying database rows. It's safe to call multiple
+// times.
+func (r *Rows) Close() error {
+	r.current = nil
+	return r.rows.Close()
+}
+
+func (r *Rows) setErr(err error) {
+	if err != nil {
+		r.err = wrapError(r.ctx, err)
+	}
+}
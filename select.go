@@ -0,0 +1,216 @@
+package kin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Querier generates queries. It's implemented by both Database and
+// Transaction.
+type Querier interface {
+	// Query generates a new query to be executed at a later time.
+	Query(stmt string, params ...interface{}) *Query
+}
+
+// SelectStatement builds a SELECT query one clause at a time. It isn't an ORM:
+// each clause is written in SQL, and the builder only takes care of putting
+// the clauses together and numbering their parameters.
+//
+// Parameters inside of a clause are numbered from $1, and are renumbered to
+// their position in the final statement when it's built:
+//
+//	q := kin.Select("u.*").
+//		From("users u").
+//		Join("JOIN teams t ON t.id = u.team_id").
+//		Where("t.name = $1", team).
+//		Where("u.created_at > $1", since).
+//		OrderBy("u.created_at DESC").
+//		Limit(20).
+//		Query(db)
+//
+// builds:
+//
+//	SELECT u.* FROM users u JOIN teams t ON t.id = u.team_id
+//	WHERE (t.name = $1) AND (u.created_at > $2)
+//	ORDER BY u.created_at DESC LIMIT $3
+type SelectStatement struct {
+	columns []string
+	from    string
+	joins   []sqlClause
+	where   []sqlClause
+	groupBy []string
+	having  []sqlClause
+	orderBy []string
+	limit   *int
+	offset  *int
+}
+
+type sqlClause struct {
+	sql    string
+	params []interface{}
+}
+
+// Select starts a new SELECT statement for the columns. With no columns, all
+// columns are selected.
+func Select(columns ...string) *SelectStatement {
+	return &SelectStatement{columns: columns}
+}
+
+// From sets the table, or other from item, that's selected from.
+func (s *SelectStatement) From(table string) *SelectStatement {
+	s.from = table
+	return s
+}
+
+// Join adds a join clause, including the join keyword, such as
+// "LEFT JOIN teams t ON t.id = u.team_id".
+func (s *SelectStatement) Join(join string, params ...interface{}) *SelectStatement {
+	s.joins = append(s.joins, sqlClause{join, params})
+	return s
+}
+
+// Where adds a condition to the WHERE clause. Multiple conditions are
+// combined with AND.
+func (s *SelectStatement) Where(condition string, params ...interface{}) *SelectStatement {
+	s.where = append(s.where, sqlClause{condition, params})
+	return s
+}
+
+// GroupBy adds expressions to the GROUP BY clause.
+func (s *SelectStatement) GroupBy(expressions ...string) *SelectStatement {
+	s.groupBy = append(s.groupBy, expressions...)
+	return s
+}
+
+// Having adds a condition to the HAVING clause. Multiple conditions are
+// combined with AND.
+func (s *SelectStatement) Having(condition string, params ...interface{}) *SelectStatement {
+	s.having = append(s.having, sqlClause{condition, params})
+	return s
+}
+
+// OrderBy adds expressions to the ORDER BY clause, such as "created_at DESC".
+func (s *SelectStatement) OrderBy(expressions ...string) *SelectStatement {
+	s.orderBy = append(s.orderBy, expressions...)
+	return s
+}
+
+// Limit sets the maximum number of rows that are returned.
+func (s *SelectStatement) Limit(limit int) *SelectStatement {
+	s.limit = &limit
+	return s
+}
+
+// Offset sets the number of rows that are skipped before rows are returned.
+func (s *SelectStatement) Offset(offset int) *SelectStatement {
+	s.offset = &offset
+	return s
+}
+
+// Build generates the statement and its parameters.
+func (s *SelectStatement) Build() (string, []interface{}, error) {
+	if s.from == "" {
+		return "", nil, errors.New("select statement must have a from clause")
+	}
+
+	var b selectBuilder
+
+	columns := "*"
+	if len(s.columns) > 0 {
+		columns = strings.Join(s.columns, ", ")
+	}
+
+	b.sql.WriteString(fmt.Sprintf("SELECT %s FROM %s", columns, s.from))
+
+	for _, join := range s.joins {
+		b.sql.WriteString(" ")
+		b.add(join)
+	}
+
+	b.conditions(" WHERE ", s.where)
+
+	if len(s.groupBy) > 0 {
+		b.sql.WriteString(" GROUP BY " + strings.Join(s.groupBy, ", "))
+	}
+
+	b.conditions(" HAVING ", s.having)
+
+	if len(s.orderBy) > 0 {
+		b.sql.WriteString(" ORDER BY " + strings.Join(s.orderBy, ", "))
+	}
+
+	if s.limit != nil {
+		b.sql.WriteString(" LIMIT ")
+		b.add(sqlClause{"$1", []interface{}{*s.limit}})
+	}
+
+	if s.offset != nil {
+		b.sql.WriteString(" OFFSET ")
+		b.add(sqlClause{"$1", []interface{}{*s.offset}})
+	}
+
+	if b.err != nil {
+		return "", nil, b.err
+	}
+
+	return b.sql.String(), b.params, nil
+}
+
+// Query generates a query for the statement with the querier, which is
+// usually a Database or Transaction.
+func (s *SelectStatement) Query(q Querier) *Query {
+	stmt, params, err := s.Build()
+	return q.Query(stmt, params...).withErr(err)
+}
+
+// selectBuilder accumulates the SQL and parameters of a statement while it's
+// being built, keeping the first error that occurs.
+type selectBuilder struct {
+	sql    strings.Builder
+	params []interface{}
+	err    error
+}
+
+func (b *selectBuilder) conditions(keyword string, clauses []sqlClause) {
+	for i, clause := range clauses {
+		if i == 0 {
+			b.sql.WriteString(keyword)
+		} else {
+			b.sql.WriteString(" AND ")
+		}
+
+		b.sql.WriteString("(")
+		b.add(clause)
+		b.sql.WriteString(")")
+	}
+}
+
+// add appends the clause, renumbering its parameters to follow the ones that
+// have already been added.
+func (b *selectBuilder) add(clause sqlClause) {
+	if b.err != nil {
+		return
+	}
+
+	offset := len(b.params)
+	sql, err := rewritePlaceholders(clause.sql, func(p placeholder) (string, error) {
+		if p.name != "" {
+			return p.raw, nil
+		}
+
+		if p.position < 1 || p.position > len(clause.params) {
+			return "", fmt.Errorf("parameter %s in %q has no value", p, clause.sql)
+		}
+
+		return fmt.Sprintf("$%d", p.position+offset), nil
+	})
+
+	if err != nil {
+		b.err = err
+		return
+	}
+
+	b.sql.WriteString(sql)
+	b.params = append(b.params, clause.params...)
+}
@@ -0,0 +1,69 @@
+package kin
+
+import (
+	"testing"
+)
+
+func TestSelectBuild(t *testing.T) {
+	stmt, params, err := Select("u.*", "count(o.id) AS orders").
+		From("users u").
+		Join("LEFT JOIN orders o ON o.user_id = u.id AND o.status = $1", "paid").
+		Where("u.team = $1", "kin").
+		Where("u.created_at > $1 OR u.name = $2", "2018-01-01", "Donkey Hote").
+		GroupBy("u.id").
+		Having("count(o.id) > $1", 2).
+		OrderBy("u.created_at DESC", "u.id").
+		Limit(20).
+		Offset(40).
+		Build()
+
+	if err != nil {
+		t.Errorf("Build() = (_, _, %v), want (_, _, <nil>)", err)
+		return
+	}
+
+	wantStmt := "SELECT u.*, count(o.id) AS orders FROM users u " +
+		"LEFT JOIN orders o ON o.user_id = u.id AND o.status = $1 " +
+		"WHERE (u.team = $2) AND (u.created_at > $3 OR u.name = $4) " +
+		"GROUP BY u.id HAVING (count(o.id) > $5) " +
+		"ORDER BY u.created_at DESC, u.id LIMIT $6 OFFSET $7"
+	if stmt != wantStmt {
+		t.Errorf("Build() = %q, want %q", stmt, wantStmt)
+	}
+
+	wantParams := []interface{}{"paid", "kin", "2018-01-01", "Donkey Hote", 2, 20, 40}
+	if len(params) != len(wantParams) {
+		t.Errorf("Build() params = %v, want %v", params, wantParams)
+		return
+	}
+
+	for i, param := range params {
+		if param != wantParams[i] {
+			t.Errorf("Build() params[%d] = %v, want %v", i, param, wantParams[i])
+		}
+	}
+}
+
+func TestSelectBuildErrors(t *testing.T) {
+	if _, _, err := Select().Build(); err == nil {
+		t.Error("Select().Build() = (_, _, <nil>), want (_, _, error)")
+	}
+
+	if _, _, err := Select().From("users").Where("id = $2", 1).Build(); err == nil {
+		t.Error("Where(\"id = $2\", 1).Build() = (_, _, <nil>), want (_, _, error)")
+	}
+}
+
+func TestSelectQuery(t *testing.T) {
+	db := &database{}
+	q := Select().From("users").Where("id = $1", 1).Query(db)
+
+	wantStmt := "SELECT * FROM users WHERE (id = $1)"
+	if q.stmt != wantStmt {
+		t.Errorf("q.stmt = %s, want %s", q.stmt, wantStmt)
+	}
+
+	if len(q.params) != 1 || q.params[0] != 1 {
+		t.Errorf("q.params = %v, want [1]", q.params)
+	}
+}
@@ -0,0 +1,128 @@
+package kin
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// defaultStatementCacheSize is the number of prepared statements each
+// database keeps around when no StatementCacheSize option is provided.
+const defaultStatementCacheSize = 100
+
+// stmtCache is a bounded, least-recently-used cache of prepared statements
+// keyed by their SQL text.
+type stmtCache struct {
+	db   *sql.DB
+	size int
+
+	mu    sync.Mutex
+	order *list.List
+	stmts map[string]*list.Element
+}
+
+type cachedStmt struct {
+	query string
+	stmt  *sql.Stmt
+
+	// refs counts the callers that are using the statement. An evicted
+	// statement is only closed once the last of them releases it.
+	refs    int
+	evicted bool
+}
+
+func newStmtCache(db *sql.DB, size int) *stmtCache {
+	return &stmtCache{
+		db:    db,
+		size:  size,
+		order: list.New(),
+		stmts: map[string]*list.Element{},
+	}
+}
+
+// prepare returns the cached statement for the query, preparing it if it
+// hasn't been seen yet or was evicted. The statement must be given back with
+// release once it's been run.
+func (c *stmtCache) prepare(ctx context.Context, query string) (*cachedStmt, error) {
+	c.mu.Lock()
+	if elem, ok := c.stmts[query]; ok {
+		c.order.MoveToFront(elem)
+		cached := elem.Value.(*cachedStmt)
+		cached.refs++
+		c.mu.Unlock()
+		return cached, nil
+	}
+	c.mu.Unlock()
+
+	// Prepare outside of the lock so that a slow prepare doesn't block other
+	// queries from using the cache.
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.stmts[query]; ok {
+		// Another caller prepared the same query concurrently.
+		stmt.Close()
+		c.order.MoveToFront(elem)
+		cached := elem.Value.(*cachedStmt)
+		cached.refs++
+		return cached, nil
+	}
+
+	cached := &cachedStmt{query: query, stmt: stmt, refs: 1}
+	c.stmts[query] = c.order.PushFront(cached)
+	for c.order.Len() > c.size {
+		c.evict(c.order.Back())
+	}
+
+	return cached, nil
+}
+
+// release gives back a statement returned by prepare, closing it if it was
+// evicted while it was in use.
+func (c *stmtCache) release(cached *cachedStmt) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached.refs--
+	if cached.evicted && cached.refs == 0 {
+		return cached.stmt.Close()
+	}
+
+	return nil
+}
+
+// close closes every statement in the cache and empties it.
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstErr error
+	for c.order.Len() > 0 {
+		if err := c.evict(c.order.Back()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+func (c *stmtCache) evict(elem *list.Element) error {
+	cached := c.order.Remove(elem).(*cachedStmt)
+	delete(c.stmts, cached.query)
+
+	// A statement that another caller is about to run is closed when it's
+	// released. Statements that are still in use by open rows are closed by
+	// database/sql once those rows are released.
+	cached.evicted = true
+	if cached.refs > 0 {
+		return nil
+	}
+
+	return cached.stmt.Close()
+}
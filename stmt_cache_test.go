@@ -0,0 +1,222 @@
+package kin
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	_ "github.com/jmataya/renv/autoload"
+	_ "github.com/lib/pq"
+)
+
+func TestStmtCacheEviction(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, _ := sql.Open("postgres", connStr)
+	defer db.Close()
+
+	ctx := context.Background()
+	cache := newStmtCache(db, 2)
+	defer cache.close()
+
+	queries := []string{"SELECT 1", "SELECT 2", "SELECT 1", "SELECT 3"}
+	for _, query := range queries {
+		cached, err := cache.prepare(ctx, query)
+		if err != nil {
+			t.Errorf("cache.prepare(%s) = %v, want <nil>", query, err)
+			return
+		}
+		cache.release(cached)
+	}
+
+	if cache.order.Len() != 2 {
+		t.Errorf("cache.order.Len() = %d, want 2", cache.order.Len())
+	}
+
+	if _, ok := cache.stmts["SELECT 2"]; ok {
+		t.Error("cache.stmts[\"SELECT 2\"] is cached, want evicted")
+	}
+
+	for _, query := range []string{"SELECT 1", "SELECT 3"} {
+		if _, ok := cache.stmts[query]; !ok {
+			t.Errorf("cache.stmts[%q] is evicted, want cached", query)
+		}
+	}
+}
+
+func TestQueryWithoutPreparedStatements(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, err := NewConnection(connStr, DisablePreparedStatements())
+	if err != nil {
+		t.Errorf("NewConnection(%s) = %v, want <nil>", connStr, err)
+		return
+	}
+	defer db.Close()
+
+	res, err := db.Query("SELECT $1::int AS n", 42).One()
+	if err != nil {
+		t.Errorf("db.Query(...).One() = %v, want <nil>", err)
+		return
+	}
+
+	if n := res.ExtractInt("n"); n != 42 {
+		t.Errorf("res.ExtractInt(\"n\") = %d, want 42", n)
+	}
+}
+
+func TestStmtCacheEvictInUse(t *testing.T) {
+	connector := &stubConnector{}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	ctx := context.Background()
+	cache := newStmtCache(db, 1)
+	defer cache.close()
+
+	first, err := cache.prepare(ctx, "SELECT 1")
+	if err != nil {
+		t.Errorf("cache.prepare(SELECT 1) = %v, want <nil>", err)
+		return
+	}
+
+	second, err := cache.prepare(ctx, "SELECT 2")
+	if err != nil {
+		t.Errorf("cache.prepare(SELECT 2) = %v, want <nil>", err)
+		return
+	}
+	defer cache.release(second)
+
+	// The first statement was evicted, but it's still in use.
+	if _, err := first.stmt.ExecContext(ctx); err != nil {
+		t.Errorf("first.stmt.ExecContext(...) = %v, want <nil>", err)
+	}
+
+	if closed := atomic.LoadInt64(&connector.closed); closed != 0 {
+		t.Errorf("closed statements = %d before release, want 0", closed)
+	}
+
+	cache.release(first)
+	if closed := atomic.LoadInt64(&connector.closed); closed != 1 {
+		t.Errorf("closed statements = %d after release, want 1", closed)
+	}
+}
+
+func TestStmtCacheConcurrentEviction(t *testing.T) {
+	db := sql.OpenDB(&stubConnector{})
+	defer db.Close()
+
+	ctx := context.Background()
+	cache := newStmtCache(db, 2)
+	defer cache.close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 800)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				cached, err := cache.prepare(ctx, fmt.Sprintf("SELECT %d", (i+j)%5))
+				if err != nil {
+					errs <- err
+					continue
+				}
+
+				rows, err := cached.stmt.QueryContext(ctx)
+				cache.release(cached)
+				if err != nil {
+					errs <- err
+					continue
+				}
+				rows.Close()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("cached.stmt.QueryContext(...) = %v, want <nil>", err)
+	}
+}
+
+// stubConnector is a driver that prepares statements that return no rows, and
+// counts how many of them are closed.
+type stubConnector struct {
+	closed int64
+}
+
+func (c *stubConnector) Connect(context.Context) (driver.Conn, error) {
+	return stubConn{c}, nil
+}
+
+func (c *stubConnector) Driver() driver.Driver {
+	return nil
+}
+
+type stubConn struct {
+	connector *stubConnector
+}
+
+func (c stubConn) Prepare(query string) (driver.Stmt, error) {
+	return stubStmt(c), nil
+}
+
+func (c stubConn) Close() error {
+	return nil
+}
+
+func (c stubConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions aren't supported")
+}
+
+type stubStmt struct {
+	connector *stubConnector
+}
+
+func (s stubStmt) Close() error {
+	atomic.AddInt64(&s.connector.closed, 1)
+	return nil
+}
+
+func (s stubStmt) NumInput() int {
+	return 0
+}
+
+func (s stubStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (s stubStmt) Query([]driver.Value) (driver.Rows, error) {
+	return stubRows{}, nil
+}
+
+type stubRows struct{}
+
+func (stubRows) Columns() []string {
+	return nil
+}
+
+func (stubRows) Close() error {
+	return nil
+}
+
+func (stubRows) Next([]driver.Value) error {
+	return io.EOF
+}
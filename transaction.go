@@ -1,54 +1,498 @@
 package kin
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 // Transaction is an interface for interacting with a database transaction. It
 // abstracts away managing connection pools and various low-level bits.
 type Transaction interface {
-	// Commit commits the transaction with the database.
+	// Commit commits the transaction with the database. For a nested
+	// transaction, it releases the transaction's savepoint instead, leaving
+	// the outcome to the enclosing transaction.
 	Commit() error
 
+	// CopyFrom loads the models into their table using COPY ... FROM STDIN.
+	// The copied columns are the ones set in the first model, and every model
+	// must provide those columns. A model that sets any other column is an
+	// error. It returns the number of rows copied.
+	CopyFrom(models []Model) (int64, error)
+
+	// CopyFromContext is like CopyFrom, but runs with the provided context.
+	CopyFromContext(ctx context.Context, models []Model) (int64, error)
+
+	// CopyFromChan is like CopyFrom, but reads models from a channel until
+	// it's closed.
+	CopyFromChan(models <-chan Model) (int64, error)
+
+	// CopyFromChanContext is like CopyFromChan, but runs with the provided
+	// context.
+	CopyFromChanContext(ctx context.Context, models <-chan Model) (int64, error)
+
+	// CopyTo runs a query and writes its rows to w in the chosen COPY format.
+	// It returns the number of rows written. This isn't a server-side COPY
+	// ... TO STDOUT, which the driver doesn't support: the query's rows are
+	// read as usual and encoded on the client, which is slower for large
+	// exports.
+	CopyTo(w io.Writer, format CopyFormat, stmt string, params ...interface{}) (int64, error)
+
+	// CopyToContext is like CopyTo, but runs with the provided context.
+	CopyToContext(ctx context.Context, w io.Writer, format CopyFormat, stmt string, params ...interface{}) (int64, error)
+
+	// DeclareCursor declares a server-side cursor for a query, which reads
+	// its rows in batches. The cursor can only move forward.
+	DeclareCursor(stmt string, params ...interface{}) (*Cursor, error)
+
+	// DeclareCursorContext is like DeclareCursor, but runs with the provided
+	// context.
+	DeclareCursorContext(ctx context.Context, stmt string, params ...interface{}) (*Cursor, error)
+
+	// DeclareScrollCursor is like DeclareCursor, but the cursor can also move
+	// backward. Depending on the query, this can make it slower to read.
+	DeclareScrollCursor(stmt string, params ...interface{}) (*Cursor, error)
+
+	// DeclareScrollCursorContext is like DeclareScrollCursor, but runs with
+	// the provided context.
+	DeclareScrollCursorContext(ctx context.Context, stmt string, params ...interface{}) (*Cursor, error)
+
+	// Delete generates a query that deletes a model by its primary key and
+	// returns the deleted row.
+	Delete(m PrimaryKeyer) *Query
+
 	// Exec runs a query against the database that doesn't return any results.
-	Exec(query string, args ...interface{}) error
+	// The query isn't prepared, so it may contain multiple statements when
+	// there are no arguments.
+	Exec(query string, args ...interface{}) (*ExecResult, error)
+
+	// ExecContext is like Exec, but runs the query with the provided context.
+	ExecContext(ctx context.Context, query string, args ...interface{}) (*ExecResult, error)
+
+	// Find generates a query that looks up a model by its primary key.
+	Find(m PrimaryKeyer, key interface{}) *Query
+
+	// Insert generates an insert query for a model.
+	Insert(m Model) *Query
 
-	// Rollback the transaction when an error occurs.
+	// InsertMany inserts all of the models with as few statements as possible
+	// and updates each model with its inserted row.
+	InsertMany(models []Model) error
+
+	// InsertManyContext is like InsertMany, but runs with the provided
+	// context.
+	InsertManyContext(ctx context.Context, models []Model) error
+
+	// Rollback the transaction when an error occurs. For a nested
+	// transaction, only the changes made since its savepoint are rolled back.
 	Rollback() error
 
-	// StartTransaction
+	// StartTransaction starts a nested transaction by creating a savepoint
+	// inside of this transaction.
 	StartTransaction() (Transaction, error)
 
+	// StartTransactionContext is like StartTransaction, but creates the
+	// savepoint with the provided context.
+	StartTransactionContext(ctx context.Context) (Transaction, error)
+
+	// NamedQuery generates a new query with named parameters, written as
+	// :name or @name, that are bound from params. Every named parameter in
+	// the statement must have a value and every value must be used.
+	NamedQuery(stmt string, params map[string]interface{}) *Query
+
+	// NamedModelQuery generates a new query with named parameters, written
+	// as :name or @name, that are bound from the model's columns by their
+	// field names.
+	NamedModelQuery(stmt string, m Model) *Query
+
+	// Notify sends a notification with the payload to the listeners of the
+	// channel. It's only delivered once the transaction is committed, and not
+	// at all if it's rolled back.
+	Notify(channel, payload string) error
+
+	// NotifyContext is like Notify, but runs with the provided context.
+	NotifyContext(ctx context.Context, channel, payload string) error
+
 	// Query generates a new query to be executed at a later time.
 	Query(stmt string, params ...interface{}) *Query
+
+	// Update generates a query that updates the set columns of a model by its
+	// primary key and returns the updated row.
+	Update(m PrimaryKeyer) *Query
+
+	// Upsert generates an insert query for a model that resolves conflicts
+	// with existing rows as described by conflict.
+	Upsert(m Model, conflict OnConflict) *Query
+}
+
+// TxOptions holds the options used when starting a transaction. Postgres sets
+// timeouts in whole milliseconds, so the timeouts are rounded up to the next
+// millisecond.
+type TxOptions struct {
+	// Isolation is the transaction's isolation level. When it's
+	// sql.LevelDefault, the server's default is used.
+	Isolation sql.IsolationLevel
+
+	// ReadOnly starts the transaction in read only mode.
+	ReadOnly bool
+
+	// Deferrable starts the transaction in deferrable mode. It only has an
+	// effect on serializable, read only transactions, which then wait to
+	// acquire a snapshot that can't cause a serialization failure.
+	Deferrable bool
+
+	// StatementTimeout aborts any statement in the transaction that takes
+	// longer than the timeout. Zero uses the session's setting.
+	StatementTimeout time.Duration
+
+	// LockTimeout aborts any statement in the transaction that waits on a
+	// lock for longer than the timeout. Zero uses the session's setting.
+	LockTimeout time.Duration
+
+	// IdleInTransactionSessionTimeout terminates the session if the
+	// transaction is idle for longer than the timeout. Zero uses the
+	// session's setting.
+	IdleInTransactionSessionTimeout time.Duration
+}
+
+// settings generates the statements that apply the options that can't be set
+// when beginning the transaction.
+func (o TxOptions) settings() []string {
+	var stmts []string
+	if o.Deferrable {
+		stmts = append(stmts, "SET TRANSACTION DEFERRABLE")
+	}
+
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"statement_timeout", o.StatementTimeout},
+		{"lock_timeout", o.LockTimeout},
+		{"idle_in_transaction_session_timeout", o.IdleInTransactionSessionTimeout},
+	}
+
+	for _, timeout := range timeouts {
+		if timeout.value > 0 {
+			// Timeouts are set in milliseconds, and zero disables them, so
+			// partial milliseconds are rounded up.
+			ms := (timeout.value + time.Millisecond - 1) / time.Millisecond
+			stmts = append(stmts, fmt.Sprintf("SET LOCAL %s = %d", timeout.name, ms))
+		}
+	}
+
+	return stmts
+}
+
+// TransactionOption configures how a function is run by WithTransaction.
+type TransactionOption func(*transactionOptions)
+
+type transactionOptions struct {
+	maxAttempts int
+	backoff     time.Duration
+	txOptions   TxOptions
+}
+
+// WithTxOptions starts the transaction with the provided options.
+func WithTxOptions(opts TxOptions) TransactionOption {
+	return func(o *transactionOptions) {
+		o.txOptions = opts
+	}
+}
+
+// RetryOnConflict retries the whole transaction when it fails because of a
+// serialization failure (SQLSTATE 40001) or a deadlock (SQLSTATE 40P01). The
+// function is run at most maxAttempts times, waiting between attempts for
+// backoff, doubled after every attempt.
+func RetryOnConflict(maxAttempts int, backoff time.Duration) TransactionOption {
+	return func(o *transactionOptions) {
+		o.maxAttempts = maxAttempts
+		o.backoff = backoff
+	}
 }
 
 type transaction struct {
-	tx *sql.Tx
+	tx    *sql.Tx
+	stmts *stmtCache
+
+	// savepoint is the name of the savepoint backing a nested transaction. It's
+	// empty for the outermost transaction.
+	savepoint string
+
+	// parent is the transaction that a nested transaction was started from.
+	parent *transaction
+
+	// names counts the savepoints and cursors created in the outermost
+	// transaction so that each one gets a unique name.
+	names *int
+
+	// cursors are the cursors declared in the transaction that are still
+	// open.
+	cursors []*Cursor
+
+	done bool
+}
+
+func newTransaction(tx *sql.Tx, stmts *stmtCache) *transaction {
+	return &transaction{tx: tx, stmts: stmts, names: new(int)}
 }
 
 func (t *transaction) Commit() error {
-	return t.tx.Commit()
+	if t.savepoint == "" {
+		defer t.closeCursors()
+		return wrapError(context.Background(), t.tx.Commit())
+	}
+
+	if err := t.finishSavepoint("RELEASE SAVEPOINT " + t.savepoint); err != nil {
+		return err
+	}
+
+	// Cursors declared in a released savepoint stay open until the enclosing
+	// transaction ends.
+	t.parent.cursors = append(t.parent.cursors, t.cursors...)
+	t.cursors = nil
+	return nil
+}
+
+func (t *transaction) CopyFrom(models []Model) (int64, error) {
+	return t.CopyFromContext(context.Background(), models)
+}
+
+func (t *transaction) CopyFromContext(ctx context.Context, models []Model) (int64, error) {
+	return copyFrom(ctx, t.tx, modelSlice(models))
+}
+
+func (t *transaction) CopyFromChan(models <-chan Model) (int64, error) {
+	return t.CopyFromChanContext(context.Background(), models)
+}
+
+func (t *transaction) CopyFromChanContext(ctx context.Context, models <-chan Model) (int64, error) {
+	return copyFrom(ctx, t.tx, modelChan(ctx, models))
+}
+
+func (t *transaction) CopyTo(w io.Writer, format CopyFormat, stmt string, params ...interface{}) (int64, error) {
+	return t.CopyToContext(context.Background(), w, format, stmt, params...)
+}
+
+func (t *transaction) CopyToContext(ctx context.Context, w io.Writer, format CopyFormat, stmt string, params ...interface{}) (int64, error) {
+	return copyTo(ctx, t.Query(stmt, params...), w, format)
+}
+
+func (t *transaction) DeclareCursor(stmt string, params ...interface{}) (*Cursor, error) {
+	return t.DeclareCursorContext(context.Background(), stmt, params...)
 }
 
-func (t *transaction) Exec(query string, args ...interface{}) error {
-	_, err := t.tx.Exec(query, args...)
-	return err
+func (t *transaction) DeclareCursorContext(ctx context.Context, stmt string, params ...interface{}) (*Cursor, error) {
+	return declareCursor(ctx, t, false, stmt, params...)
+}
+
+func (t *transaction) DeclareScrollCursor(stmt string, params ...interface{}) (*Cursor, error) {
+	return t.DeclareScrollCursorContext(context.Background(), stmt, params...)
+}
+
+func (t *transaction) DeclareScrollCursorContext(ctx context.Context, stmt string, params ...interface{}) (*Cursor, error) {
+	return declareCursor(ctx, t, true, stmt, params...)
+}
+
+func (t *transaction) Delete(m PrimaryKeyer) *Query {
+	stmt, params, err := deleteStmt(m)
+	return t.Query(stmt, params...).withErr(err)
+}
+
+func (t *transaction) Exec(query string, args ...interface{}) (*ExecResult, error) {
+	return t.ExecContext(context.Background(), query, args...)
+}
+
+func (t *transaction) ExecContext(ctx context.Context, query string, args ...interface{}) (*ExecResult, error) {
+	query, args, err := bindParams(query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return exec(ctx, t.tx, query, args...)
+}
+
+func (t *transaction) Find(m PrimaryKeyer, key interface{}) *Query {
+	stmt, params := findStmt(m, key)
+	return t.Query(stmt, params...)
+}
+
+func (t *transaction) Insert(m Model) *Query {
+	stmt, params := insertStmt(m)
+	return t.Query(stmt, params...)
+}
+
+func (t *transaction) InsertMany(models []Model) error {
+	return t.InsertManyContext(context.Background(), models)
+}
+
+func (t *transaction) InsertManyContext(ctx context.Context, models []Model) error {
+	chunks, err := insertManyChunks(models)
+	if err != nil {
+		return err
+	}
+
+	return insertMany(ctx, t.Query, chunks)
 }
 
 func (t *transaction) Rollback() error {
-	return t.tx.Rollback()
+	if t.savepoint == "" {
+		defer t.closeCursors()
+		return t.tx.Rollback()
+	}
+
+	// Rolling back to a savepoint keeps it around, so it's released as well.
+	if err := t.finishSavepoint(fmt.Sprintf("ROLLBACK TO SAVEPOINT %s; RELEASE SAVEPOINT %s", t.savepoint, t.savepoint)); err != nil {
+		return err
+	}
+
+	// Postgres closes the cursors that were declared since the savepoint.
+	t.closeCursors()
+	return nil
 }
 
 func (t *transaction) StartTransaction() (Transaction, error) {
-	return nil, errors.New("transaction already started")
+	return t.StartTransactionContext(context.Background())
+}
+
+func (t *transaction) StartTransactionContext(ctx context.Context) (Transaction, error) {
+	if t.done {
+		return nil, sql.ErrTxDone
+	}
+
+	*t.names++
+	savepoint := fmt.Sprintf("kin_savepoint_%d", *t.names)
+	if _, err := t.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return nil, err
+	}
+
+	return &transaction{
+		tx:        t.tx,
+		stmts:     t.stmts,
+		savepoint: savepoint,
+		parent:    t,
+		names:     t.names,
+	}, nil
+}
+
+func (t *transaction) NamedQuery(stmt string, params map[string]interface{}) *Query {
+	return namedQuery(t.Query, stmt, params)
+}
+
+func (t *transaction) NamedModelQuery(stmt string, m Model) *Query {
+	return namedModelQuery(t.Query, stmt, m)
+}
+
+func (t *transaction) Notify(channel, payload string) error {
+	return t.NotifyContext(context.Background(), channel, payload)
+}
+
+func (t *transaction) NotifyContext(ctx context.Context, channel, payload string) error {
+	return notify(ctx, t.ExecContext, channel, payload)
 }
 
 func (t *transaction) Query(stmt string, params ...interface{}) *Query {
 	return &Query{
 		db:     t.tx,
+		tx:     t.tx,
+		stmts:  t.stmts,
 		stmt:   stmt,
 		params: params,
 	}
 }
+
+// closeCursors marks the transaction's cursors as closed once the server has
+// closed them.
+func (t *transaction) closeCursors() {
+	for _, c := range t.cursors {
+		c.closed = true
+	}
+
+	t.cursors = nil
+}
+
+func (t *transaction) finishSavepoint(stmt string) error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+
+	if _, err := t.Exec(stmt); err != nil {
+		return err
+	}
+
+	t.done = true
+	return nil
+}
+
+func (t *transaction) Update(m PrimaryKeyer) *Query {
+	stmt, params, err := updateStmt(m)
+	return t.Query(stmt, params...).withErr(err)
+}
+
+func (t *transaction) Upsert(m Model, conflict OnConflict) *Query {
+	stmt, params, err := upsertStmt(m, conflict)
+	return t.Query(stmt, params...).withErr(err)
+}
+
+// withTransaction runs fn inside of a transaction, retrying it as configured
+// by the options.
+func withTransaction(ctx context.Context, start func(context.Context, TxOptions) (Transaction, error), fn func(Transaction) error, opts ...TransactionOption) error {
+	o := transactionOptions{maxAttempts: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	backoff := o.backoff
+	for attempt := 1; ; attempt++ {
+		err := runTransaction(ctx, start, o.txOptions, fn)
+		if err == nil || attempt >= o.maxAttempts || !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return wrapError(ctx, err)
+		}
+
+		backoff *= 2
+	}
+}
+
+// runTransaction runs fn inside of a single transaction, committing it if fn
+// succeeds and rolling it back if fn returns an error or panics. Panics are
+// propagated after the rollback.
+func runTransaction(ctx context.Context, start func(context.Context, TxOptions) (Transaction, error), opts TxOptions, fn func(Transaction) error) error {
+	txn, err := start(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			txn.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(txn); err != nil {
+		txn.Rollback()
+		return err
+	}
+
+	return txn.Commit()
+}
+
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		err = newError(pqErr)
+	}
+
+	return errors.Is(err, ErrSerializationFailure) || errors.Is(err, ErrDeadlock)
+}
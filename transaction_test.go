@@ -0,0 +1,264 @@
+package kin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+
+	_ "github.com/jmataya/renv/autoload"
+)
+
+func TestNestedTransaction(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, err := NewConnection(connStr)
+	if err != nil {
+		t.Errorf("NewConnection(%s) = %v, want <nil>", connStr, err)
+		return
+	}
+	defer db.Close()
+
+	txn, err := db.StartTransaction()
+	if err != nil {
+		t.Errorf("db.StartTransaction() = (_, %v), want (_, <nil>)", err)
+		return
+	}
+	defer txn.Rollback()
+
+	if _, err := txn.Exec("CREATE TEMP TABLE nested (id int) ON COMMIT DROP"); err != nil {
+		t.Errorf("txn.Exec(...) = %v, want <nil>", err)
+		return
+	}
+
+	if _, err := txn.Exec("INSERT INTO nested VALUES (1)"); err != nil {
+		t.Errorf("txn.Exec(...) = %v, want <nil>", err)
+		return
+	}
+
+	rolledBack, err := txn.StartTransaction()
+	if err != nil {
+		t.Errorf("txn.StartTransaction() = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	if _, err := rolledBack.Exec("INSERT INTO nested VALUES (2)"); err != nil {
+		t.Errorf("rolledBack.Exec(...) = %v, want <nil>", err)
+		return
+	}
+
+	if err := rolledBack.Rollback(); err != nil {
+		t.Errorf("rolledBack.Rollback() = %v, want <nil>", err)
+	}
+
+	if err := rolledBack.Commit(); err != sql.ErrTxDone {
+		t.Errorf("rolledBack.Commit() = %v, want %v", err, sql.ErrTxDone)
+	}
+
+	committed, err := txn.StartTransaction()
+	if err != nil {
+		t.Errorf("txn.StartTransaction() = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	if _, err := committed.Exec("INSERT INTO nested VALUES (3)"); err != nil {
+		t.Errorf("committed.Exec(...) = %v, want <nil>", err)
+		return
+	}
+
+	if err := committed.Commit(); err != nil {
+		t.Errorf("committed.Commit() = %v, want <nil>", err)
+	}
+
+	res, err := txn.Query("SELECT sum(id) AS total FROM nested").One()
+	if err != nil {
+		t.Errorf("txn.Query(...).One() = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	if total := res.ExtractInt("total"); total != 4 {
+		t.Errorf("res.ExtractInt(\"total\") = %d, want 4", total)
+	}
+}
+
+type fakeTransaction struct {
+	Transaction
+	commits   int
+	rollbacks int
+}
+
+func (f *fakeTransaction) Commit() error {
+	f.commits++
+	return nil
+}
+
+func (f *fakeTransaction) Rollback() error {
+	f.rollbacks++
+	return nil
+}
+
+func (f *fakeTransaction) start(context.Context, TxOptions) (Transaction, error) {
+	return f, nil
+}
+
+func TestWithTransactionCommit(t *testing.T) {
+	txn := new(fakeTransaction)
+	err := withTransaction(context.Background(), txn.start, func(Transaction) error {
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("withTransaction(...) = %v, want <nil>", err)
+	}
+
+	if txn.commits != 1 || txn.rollbacks != 0 {
+		t.Errorf("(commits, rollbacks) = (%d, %d), want (1, 0)", txn.commits, txn.rollbacks)
+	}
+}
+
+func TestWithTransactionRollback(t *testing.T) {
+	txn := new(fakeTransaction)
+	want := errors.New("failed")
+	err := withTransaction(context.Background(), txn.start, func(Transaction) error {
+		return want
+	})
+
+	if err != want {
+		t.Errorf("withTransaction(...) = %v, want %v", err, want)
+	}
+
+	if txn.commits != 0 || txn.rollbacks != 1 {
+		t.Errorf("(commits, rollbacks) = (%d, %d), want (0, 1)", txn.commits, txn.rollbacks)
+	}
+}
+
+func TestWithTransactionPanic(t *testing.T) {
+	txn := new(fakeTransaction)
+
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Errorf("recover() = %v, want boom", p)
+		}
+
+		if txn.commits != 0 || txn.rollbacks != 1 {
+			t.Errorf("(commits, rollbacks) = (%d, %d), want (0, 1)", txn.commits, txn.rollbacks)
+		}
+	}()
+
+	withTransaction(context.Background(), txn.start, func(Transaction) error {
+		panic("boom")
+	})
+}
+
+func TestWithTransactionRetry(t *testing.T) {
+	txn := new(fakeTransaction)
+	attempts := 0
+	err := withTransaction(context.Background(), txn.start, func(Transaction) error {
+		attempts++
+		if attempts < 3 {
+			return &pq.Error{Code: "40001"}
+		}
+		return nil
+	}, RetryOnConflict(3, 0))
+
+	if err != nil {
+		t.Errorf("withTransaction(...) = %v, want <nil>", err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+
+	attempts = 0
+	err = withTransaction(context.Background(), txn.start, func(Transaction) error {
+		attempts++
+		return &pq.Error{Code: "23505"}
+	}, RetryOnConflict(3, 0))
+
+	if err == nil || attempts != 1 {
+		t.Errorf("withTransaction(...) = %v after %d attempts, want error after 1 attempt", err, attempts)
+	}
+}
+
+func TestTxOptionsSettings(t *testing.T) {
+	opts := TxOptions{
+		Isolation:        sql.LevelSerializable,
+		ReadOnly:         true,
+		Deferrable:       true,
+		StatementTimeout: 5 * time.Second,
+		LockTimeout:      250 * time.Millisecond,
+	}
+
+	want := []string{
+		"SET TRANSACTION DEFERRABLE",
+		"SET LOCAL statement_timeout = 5000",
+		"SET LOCAL lock_timeout = 250",
+	}
+
+	got := opts.settings()
+	if len(got) != len(want) {
+		t.Errorf("opts.settings() = %v, want %v", got, want)
+		return
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("opts.settings()[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	if got := (TxOptions{}).settings(); len(got) != 0 {
+		t.Errorf("TxOptions{}.settings() = %v, want []", got)
+	}
+	opts = TxOptions{StatementTimeout: 500 * time.Microsecond, LockTimeout: 1500 * time.Microsecond}
+	want = []string{"SET LOCAL statement_timeout = 1", "SET LOCAL lock_timeout = 2"}
+	got = opts.settings()
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("opts.settings() = %v, want %v", got, want)
+	}
+}
+
+func TestStartTransactionReadOnly(t *testing.T) {
+	connStr := os.Getenv("POSTGRES_URL")
+	if connStr == "" {
+		t.Error("POSTGRES_URL is empty")
+		return
+	}
+
+	db, err := NewConnection(connStr)
+	if err != nil {
+		t.Errorf("NewConnection(%s) = %v, want <nil>", connStr, err)
+		return
+	}
+	defer db.Close()
+
+	opts := TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true, Deferrable: true, StatementTimeout: time.Second}
+	txn, err := db.StartTransactionWithOptions(context.Background(), opts)
+	if err != nil {
+		t.Errorf("db.StartTransactionWithOptions(...) = (_, %v), want (_, <nil>)", err)
+		return
+	}
+	defer txn.Rollback()
+
+	res, err := txn.Query("SHOW transaction_isolation").One()
+	if err != nil {
+		t.Errorf("txn.Query(...).One() = (_, %v), want (_, <nil>)", err)
+		return
+	}
+
+	if level := res.ExtractString("transaction_isolation"); level != "serializable" {
+		t.Errorf("transaction_isolation = %s, want serializable", level)
+	}
+
+	if _, err := txn.Exec("CREATE TEMP TABLE read_only (id int)"); err == nil {
+		t.Error("txn.Exec(...) = <nil> in a read only transaction, want error")
+	}
+}
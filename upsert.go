@@ -0,0 +1,126 @@
+package kin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ConflictAction is the action an upsert takes when the row being inserted
+// conflicts with an existing row.
+type ConflictAction int
+
+const (
+	// DoNothing skips the insert of a conflicting row. Since nothing is
+	// inserted, the upsert doesn't return a row when a conflict occurs.
+	DoNothing ConflictAction = iota
+
+	// DoUpdate updates the existing row with the values that were going to be
+	// inserted.
+	DoUpdate
+)
+
+// OnConflict describes how an upsert resolves a conflict with an existing row.
+type OnConflict struct {
+	// Columns are the columns of the unique index that's used to detect
+	// conflicts. Only one of Columns and Constraint may be set.
+	Columns []string
+
+	// Constraint is the name of the constraint that's used to detect
+	// conflicts. Only one of Columns and Constraint may be set.
+	Constraint string
+
+	// Action is what's done with a conflicting row.
+	Action ConflictAction
+
+	// Update lists the columns that are overwritten with the inserted values
+	// when the action is DoUpdate. Each one must be a column of the model
+	// that's set, since it's updated with the inserted value. If it's empty,
+	// every inserted column that's not part of the conflict target is
+	// updated.
+	Update []string
+}
+
+func upsertStmt(m Model, conflict OnConflict) (string, []interface{}, error) {
+	stmt, inserted, params := insertClause(m)
+
+	target, err := conflict.target()
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch conflict.Action {
+	case DoNothing:
+		stmt = fmt.Sprintf("%s ON CONFLICT %sDO NOTHING RETURNING *", stmt, target)
+		return stmt, params, nil
+	case DoUpdate:
+		if target == "" {
+			return "", nil, errors.New("conflict columns or constraint must be set to update on conflict")
+		}
+	default:
+		return "", nil, fmt.Errorf("unknown conflict action %d", conflict.Action)
+	}
+
+	update := conflict.Update
+	if len(update) == 0 {
+		for _, column := range inserted {
+			if !contains(conflict.Columns, column) {
+				update = append(update, column)
+			}
+		}
+	} else {
+		var columns []string
+		for _, column := range m.Columns() {
+			columns = append(columns, column.FieldName())
+		}
+
+		for _, column := range update {
+			if !contains(columns, column) {
+				return "", nil, fmt.Errorf("column %s not found in %s", column, m.TableName())
+			}
+
+			// EXCLUDED holds the column's default for a column that wasn't
+			// inserted, which would overwrite the existing value.
+			if !contains(inserted, column) {
+				return "", nil, fmt.Errorf("column %s isn't set, so it can't be updated on conflict in %s", column, m.TableName())
+			}
+		}
+	}
+
+	if len(update) == 0 {
+		return "", nil, fmt.Errorf("no columns to update on conflict in %s", m.TableName())
+	}
+
+	assignments := make([]string, len(update))
+	for i, column := range update {
+		assignments[i] = fmt.Sprintf("%s = EXCLUDED.%s", column, column)
+	}
+
+	stmt = fmt.Sprintf("%s ON CONFLICT %sDO UPDATE SET %s RETURNING *", stmt, target, strings.Join(assignments, ", "))
+	return stmt, params, nil
+}
+
+// target generates the conflict target, including a trailing space when
+// it's not empty.
+func (c OnConflict) target() (string, error) {
+	switch {
+	case len(c.Columns) > 0 && c.Constraint != "":
+		return "", errors.New("only one of conflict columns and constraint may be set")
+	case len(c.Columns) > 0:
+		return fmt.Sprintf("(%s) ", strings.Join(c.Columns, ", ")), nil
+	case c.Constraint != "":
+		return fmt.Sprintf("ON CONSTRAINT %s ", c.Constraint), nil
+	default:
+		return "", nil
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
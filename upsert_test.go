@@ -0,0 +1,84 @@
+package kin
+
+import (
+	"testing"
+)
+
+func TestUpsertDoNothing(t *testing.T) {
+	tm := testModel{ID: 1, Name: "Donkey Hote"}
+	db := &database{}
+	q := db.Upsert(tm, OnConflict{Columns: []string{"id"}})
+
+	if q.err != nil {
+		t.Errorf("q.err = %v, want <nil>", q.err)
+		return
+	}
+
+	wantStmt := "INSERT INTO test_model (id, name) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING RETURNING *"
+	if q.stmt != wantStmt {
+		t.Errorf("q.stmt = %s, want %s", q.stmt, wantStmt)
+	}
+
+	if len(q.params) != 2 {
+		t.Errorf("len(q.params) = %d, want 2", len(q.params))
+	}
+}
+
+func TestUpsertDoUpdate(t *testing.T) {
+	tm := testModel{ID: 1, Name: "Donkey Hote"}
+	db := &database{}
+
+	tests := []struct {
+		conflict OnConflict
+		wantStmt string
+	}{
+		{
+			conflict: OnConflict{Columns: []string{"id"}, Action: DoUpdate},
+			wantStmt: "INSERT INTO test_model (id, name) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name RETURNING *",
+		},
+		{
+			conflict: OnConflict{Constraint: "test_model_pkey", Action: DoUpdate, Update: []string{"name"}},
+			wantStmt: "INSERT INTO test_model (id, name) VALUES ($1, $2) ON CONFLICT ON CONSTRAINT test_model_pkey DO UPDATE SET name = EXCLUDED.name RETURNING *",
+		},
+	}
+
+	for _, test := range tests {
+		q := db.Upsert(tm, test.conflict)
+		if q.err != nil {
+			t.Errorf("q.err = %v, want <nil>", q.err)
+			continue
+		}
+
+		if q.stmt != test.wantStmt {
+			t.Errorf("q.stmt = %s, want %s", q.stmt, test.wantStmt)
+		}
+	}
+}
+
+func TestUpsertInvalidConflict(t *testing.T) {
+	tm := testModel{ID: 1, Name: "Donkey Hote"}
+	db := &database{}
+
+	conflicts := []OnConflict{
+		{Action: DoUpdate},
+		{Columns: []string{"id"}, Constraint: "test_model_pkey"},
+		{Columns: []string{"id"}, Action: DoUpdate, Update: []string{"missing"}},
+		{Columns: []string{"id", "name"}, Action: DoUpdate},
+	}
+
+	for _, conflict := range conflicts {
+		if q := db.Upsert(tm, conflict); q.err == nil {
+			t.Errorf("db.Upsert(_, %+v).err = <nil>, want error", conflict)
+		}
+	}
+}
+
+func TestUpsertUpdateUnsetColumn(t *testing.T) {
+	tm := testModel{ID: 1}
+	db := &database{}
+
+	q := db.Upsert(tm, OnConflict{Columns: []string{"id"}, Action: DoUpdate, Update: []string{"name"}})
+	if q.err == nil {
+		t.Errorf("db.Upsert(...).err = <nil>, want error for unset column name (stmt %s)", q.stmt)
+	}
+}